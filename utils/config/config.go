@@ -64,24 +64,22 @@ func Load(c interface{}) error {
 		return err
 	}
 
-	logger.Info(fmt.Sprintf(configLoadSuccessMsg, reflect.TypeOf(c).Elem()))
+	logger.Infof(configLoadSuccessMsg, reflect.TypeOf(c).Elem())
 	return nil
 }
 
 // Validate check if all the required configuration is not empty.
 // The struct filed that is required should have a tag "required: true"
-// If a required struct field value is "" then the tag value of mapstructure will be returned.
+// If a required struct field value is "" then an error with the tag value of mapstructure is added to the
+// returned MultiError.
 func Validate(c interface{}) error {
-	var missingParams []string
+	errs := errors.NewMultiError()
 	sr := reflect.ValueOf(c).Elem()
 
 	for i := 0; i < sr.NumField(); i++ {
 		if strings.TrimSpace(sr.Field(i).String()) == "" && sr.Type().Field(i).Tag.Get("required") == "true" {
-			missingParams = append(missingParams, sr.Type().Field(i).Tag.Get("mapstructure"))
+			errs.Append(errors.Newf(missingConfigErrMsg, sr.Type().Field(i).Tag.Get("mapstructure")))
 		}
 	}
-	if len(missingParams) > 0 {
-		return errors.New(fmt.Sprintf(missingConfigErrMsg, missingParams))
-	}
-	return nil
+	return errs.ErrorOrNil()
 }
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/privatesquare/bkst-go-utils/utils/slice"
+	"strings"
+)
+
+const (
+	CorsWildcard = "*"
+
+	invalidCorsOriginErrMsg      = "Invalid CORS allowed origin : %s"
+	invalidCorsCredentialsErrMsg = "CORS credentials cannot be allowed for the wildcard origin '*'"
+	invalidCorsMaxAgeErrMsg      = "Invalid CORS max age : %d"
+)
+
+// CorsConfig represents the Cross-Origin Resource Sharing configuration of a http server.
+// The list values can be set in the configuration as comma separated strings.
+// An allowed origin can contain a single wildcard "*", for example "https://*.example.com".
+type CorsConfig struct {
+	AllowedOrigins   []string `mapstructure:"SERVER_CORS_ALLOWED_ORIGINS"`
+	AllowedMethods   []string `mapstructure:"SERVER_CORS_ALLOWED_METHODS"`
+	AllowedHeaders   []string `mapstructure:"SERVER_CORS_ALLOWED_HEADERS"`
+	ExposedHeaders   []string `mapstructure:"SERVER_CORS_EXPOSED_HEADERS"`
+	AllowCredentials bool     `mapstructure:"SERVER_CORS_ALLOW_CREDENTIALS"`
+	MaxAge           int      `mapstructure:"SERVER_CORS_MAX_AGE"`
+}
+
+// Validate checks if the CORS configuration is valid.
+// The method returns a MultiError if an origin has more than one wildcard, if credentials are allowed for any
+// origin or if the max age is negative.
+func (cnf *CorsConfig) Validate() error {
+	errs := errors.NewMultiError()
+	for _, origin := range cnf.AllowedOrigins {
+		if strings.Count(origin, CorsWildcard) > 1 {
+			errs.Append(errors.Newf(invalidCorsOriginErrMsg, origin))
+		}
+	}
+	if cnf.AllowCredentials && slice.EntryExists(cnf.AllowedOrigins, CorsWildcard) {
+		errs.Append(errors.New(invalidCorsCredentialsErrMsg))
+	}
+	if cnf.MaxAge < 0 {
+		errs.Append(errors.Newf(invalidCorsMaxAgeErrMsg, cnf.MaxAge))
+	}
+	return errs.ErrorOrNil()
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	corsEnvCnf = `SERVER_HOST=localhost
+SERVER_PORT=8080
+SERVER_CORS_ALLOWED_ORIGINS=https://example.com,https://*.example.org
+SERVER_CORS_ALLOWED_METHODS=GET,POST
+SERVER_CORS_ALLOW_CREDENTIALS=true
+SERVER_CORS_MAX_AGE=600`
+)
+
+func TestCorsConfig_Validate(t *testing.T) {
+	cnf := CorsConfig{AllowedOrigins: []string{"https://example.com", "https://*.example.com"}, MaxAge: 60}
+	assert.NoError(t, cnf.Validate())
+
+	cnf.AllowedOrigins = []string{"https://*.*.example.com"}
+	assert.EqualError(t, cnf.Validate(), fmt.Sprintf(invalidCorsOriginErrMsg, "https://*.*.example.com"))
+
+	cnf.AllowedOrigins = []string{CorsWildcard}
+	cnf.AllowCredentials = true
+	assert.EqualError(t, cnf.Validate(), invalidCorsCredentialsErrMsg)
+
+	cnf.AllowCredentials = false
+	cnf.MaxAge = -1
+	assert.EqualError(t, cnf.Validate(), fmt.Sprintf(invalidCorsMaxAgeErrMsg, -1))
+
+	cnf.AllowedOrigins = []string{CorsWildcard, "https://*.*.example.com"}
+	cnf.AllowCredentials = true
+	err := cnf.Validate()
+	assert.Equal(t, 3, err.(*errors.MultiError).Len())
+	assert.Contains(t, err.Error(), invalidCorsCredentialsErrMsg)
+}
+
+func TestLoad_CorsConfig(t *testing.T) {
+	err := writeMockConfig(corsEnvCnf)
+	assert.NoError(t, err)
+	defer resetConfig()
+
+	cnf := new(ServerConfig)
+	err = Load(cnf)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com", "https://*.example.org"}, cnf.Cors.AllowedOrigins)
+	assert.Equal(t, []string{"GET", "POST"}, cnf.Cors.AllowedMethods)
+	assert.True(t, cnf.Cors.AllowCredentials)
+	assert.Equal(t, 600, cnf.Cors.MaxAge)
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"crypto/tls"
 	"fmt"
 	"github.com/privatesquare/bkst-go-utils/utils/errors"
 	"github.com/privatesquare/bkst-go-utils/utils/logger"
@@ -22,32 +23,53 @@ const (
 
 // ServerConfig represents the required configuration to run a http server.
 type ServerConfig struct {
-	Protocol string `mapstructure:"SERVER_PROTOCOL"`
-	Host     string `mapstructure:"SERVER_HOST" required:"true"`
-	Port     string `mapstructure:"SERVER_PORT" required:"true"`
-	LogLevel string `mapstructure:"SERVER_LOG_LEVEL"`
-	ProxyUrl string `mapstructure:"SERVER_PROXY_URL"`
+	Protocol string     `mapstructure:"SERVER_PROTOCOL"`
+	Host     string     `mapstructure:"SERVER_HOST" required:"true"`
+	Port     string     `mapstructure:"SERVER_PORT" required:"true"`
+	LogLevel string     `mapstructure:"SERVER_LOG_LEVEL"`
+	ProxyUrl string     `mapstructure:"SERVER_PROXY_URL"`
+	Cors     CorsConfig `mapstructure:",squash"`
+	TLS      TLSConfig  `mapstructure:",squash"`
 }
 
 // Set sets the server configuration in the global variable ServerCnf.
+// The method returns a MultiError with all the invalid server configuration values.
 func (cnf *ServerConfig) Set() error {
+	errs := errors.NewMultiError()
+
 	ServerCnf.Protocol = cnf.Protocol
-	if err := ServerCnf.validateServerProtocol(); err != nil {
-		return err
-	}
+	errs.Append(ServerCnf.validateServerProtocol())
 
 	ServerCnf.Host = cnf.Host
 	ServerCnf.Port = cnf.Port
 
 	ServerCnf.LogLevel = cnf.LogLevel
 	if err := ServerCnf.validateServerLogLevel(); err != nil {
-		return err
+		errs.Append(err)
+	} else {
+		errs.Append(logger.SetLoggerConfig(logger.GetLoggerConfig(ServerCnf.LogLevel)))
 	}
-	logger.SetLoggerConfig(logger.GetLoggerConfig(ServerCnf.LogLevel))
 
 	ServerCnf.ProxyUrl = cnf.ProxyUrl
 
-	return nil
+	if err := cnf.Cors.Validate(); err != nil {
+		errs.Append(err)
+	} else {
+		ServerCnf.Cors = cnf.Cors
+	}
+
+	if err := cnf.TLS.Validate(); err != nil {
+		errs.Append(err)
+	} else {
+		ServerCnf.TLS = cnf.TLS
+	}
+
+	return errs.ErrorOrNil()
+}
+
+// TLSConfig returns the crypto/tls configuration of the server, see TLSConfig.Load.
+func (cnf *ServerConfig) TLSConfig() (*tls.Config, error) {
+	return cnf.TLS.Load()
 }
 
 // validateServerProtocol checks if the server protocol is set to a valid value.
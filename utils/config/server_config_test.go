@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
 	"github.com/privatesquare/bkst-go-utils/utils/logger"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -30,4 +31,10 @@ func TestServerConfig_Set(t *testing.T) {
 	err = cnf.Set()
 	assert.Error(t, err)
 	assert.Equal(t, fmt.Sprintf(invalidServerLogLevelErrMsg, cnf.LogLevel), err.Error())
+
+	cnf.Protocol = "htt"
+	cnf.Cors.MaxAge = -1
+	err = cnf.Set()
+	assert.Error(t, err)
+	assert.Equal(t, 3, err.(*errors.MultiError).Len())
 }
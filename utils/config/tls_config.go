@@ -0,0 +1,122 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/privatesquare/bkst-go-utils/utils/fileutils"
+	"strings"
+)
+
+const (
+	TLSClientAuthNone             = "none"
+	TLSClientAuthRequest          = "request"
+	TLSClientAuthRequire          = "require"
+	TLSClientAuthVerifyIfGiven    = "verify_if_given"
+	TLSClientAuthRequireAndVerify = "require_and_verify"
+
+	invalidTLSKeyPairErrMsg    = "TLS certificate and key files must both be set"
+	invalidTLSClientAuthErrMsg = "Invalid TLS client auth : %s"
+	invalidTLSClientCAErrMsg   = "TLS client auth '%s' requires a client CA file"
+	invalidTLSMinVersionErrMsg = "Invalid TLS min version : %s"
+	tlsFileNotFoundErrMsg      = "TLS file '%s' was not found"
+	tlsClientCAParseErrMsg     = "No certificates found in the TLS client CA file '%s'"
+)
+
+var (
+	tlsClientAuthTypes = map[string]tls.ClientAuthType{
+		TLSClientAuthNone:             tls.NoClientCert,
+		TLSClientAuthRequest:          tls.RequestClientCert,
+		TLSClientAuthRequire:          tls.RequireAnyClientCert,
+		TLSClientAuthVerifyIfGiven:    tls.VerifyClientCertIfGiven,
+		TLSClientAuthRequireAndVerify: tls.RequireAndVerifyClientCert,
+	}
+	tlsVersions = map[string]uint16{
+		"1.2": tls.VersionTLS12,
+		"1.3": tls.VersionTLS13,
+	}
+)
+
+// TLSConfig represents the TLS configuration of a https server.
+// ClientCAFile is a PEM bundle of the certificate authorities that sign the client certificates. If it is set
+// the client auth defaults to "require_and_verify", otherwise to "none".
+type TLSConfig struct {
+	CertFile     string `mapstructure:"SERVER_TLS_CERT_FILE"`
+	KeyFile      string `mapstructure:"SERVER_TLS_KEY_FILE"`
+	ClientCAFile string `mapstructure:"SERVER_TLS_CLIENT_CA_FILE"`
+	ClientAuth   string `mapstructure:"SERVER_TLS_CLIENT_AUTH"`
+	MinVersion   string `mapstructure:"SERVER_TLS_MIN_VERSION"`
+}
+
+// Validate checks if the TLS configuration is valid.
+// The method returns a MultiError if only one of the certificate and key files is set, if a file does not exist,
+// if the client auth or the min version is not valid or if the client auth verifies certificates without a
+// client CA file.
+func (cnf *TLSConfig) Validate() error {
+	errs := errors.NewMultiError()
+	if (cnf.CertFile == "") != (cnf.KeyFile == "") {
+		errs.Append(errors.New(invalidTLSKeyPairErrMsg))
+	}
+	for _, file := range []string{cnf.CertFile, cnf.KeyFile, cnf.ClientCAFile} {
+		if file != "" && !fileutils.FileExists(file) {
+			errs.Append(errors.Newf(tlsFileNotFoundErrMsg, file))
+		}
+	}
+	clientAuth := cnf.clientAuth()
+	if _, ok := tlsClientAuthTypes[clientAuth]; !ok {
+		errs.Append(errors.Newf(invalidTLSClientAuthErrMsg, cnf.ClientAuth))
+	} else if cnf.ClientCAFile == "" &&
+		(clientAuth == TLSClientAuthVerifyIfGiven || clientAuth == TLSClientAuthRequireAndVerify) {
+		errs.Append(errors.Newf(invalidTLSClientCAErrMsg, clientAuth))
+	}
+	if _, ok := tlsVersions[cnf.MinVersion]; cnf.MinVersion != "" && !ok {
+		errs.Append(errors.Newf(invalidTLSMinVersionErrMsg, cnf.MinVersion))
+	}
+	return errs.ErrorOrNil()
+}
+
+// Load returns the crypto/tls configuration of the server with the certificate, the client CA pool and the
+// client auth type. The min version defaults to TLS 1.2.
+func (cnf *TLSConfig) Load() (*tls.Config, error) {
+	if err := cnf.Validate(); err != nil {
+		return nil, err
+	}
+	tlsCnf := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: tlsClientAuthTypes[cnf.clientAuth()],
+	}
+	if v, ok := tlsVersions[cnf.MinVersion]; ok {
+		tlsCnf.MinVersion = v
+	}
+	if cnf.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cnf.CertFile, cnf.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsCnf.Certificates = []tls.Certificate{cert}
+	}
+	if cnf.ClientCAFile != "" {
+		data, err := fileutils.ReadFile(cnf.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, errors.Newf(tlsClientCAParseErrMsg, cnf.ClientCAFile)
+		}
+		tlsCnf.ClientCAs = pool
+	}
+	return tlsCnf, nil
+}
+
+// clientAuth returns the client auth with its default value.
+func (cnf *TLSConfig) clientAuth() string {
+	switch {
+	case cnf.ClientAuth != "":
+		return strings.ToLower(cnf.ClientAuth)
+	case cnf.ClientCAFile != "":
+		return TLSClientAuthRequireAndVerify
+	default:
+		return TLSClientAuthNone
+	}
+}
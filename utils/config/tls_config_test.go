@@ -0,0 +1,92 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeSelfSignedCert writes a self-signed certificate and its key to PEM files in the directory.
+func writeSelfSignedCert(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certFile, keyFile
+}
+
+func TestTLSConfig_Validate(t *testing.T) {
+	certFile, keyFile := writeSelfSignedCert(t, t.TempDir())
+
+	cnf := TLSConfig{}
+	assert.NoError(t, cnf.Validate())
+
+	cnf = TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: certFile, MinVersion: "1.3"}
+	assert.NoError(t, cnf.Validate())
+
+	cnf = TLSConfig{CertFile: certFile}
+	assert.EqualError(t, cnf.Validate(), invalidTLSKeyPairErrMsg)
+
+	cnf = TLSConfig{ClientAuth: TLSClientAuthRequireAndVerify}
+	assert.EqualError(t, cnf.Validate(), fmt.Sprintf(invalidTLSClientCAErrMsg, TLSClientAuthRequireAndVerify))
+
+	cnf = TLSConfig{CertFile: "missing.pem", KeyFile: keyFile, ClientAuth: "always", MinVersion: "1.0"}
+	err := cnf.Validate()
+	assert.Equal(t, 3, err.(*errors.MultiError).Len())
+	assert.Contains(t, err.Error(), fmt.Sprintf(tlsFileNotFoundErrMsg, "missing.pem"))
+	assert.Contains(t, err.Error(), fmt.Sprintf(invalidTLSClientAuthErrMsg, "always"))
+	assert.Contains(t, err.Error(), fmt.Sprintf(invalidTLSMinVersionErrMsg, "1.0"))
+}
+
+func TestServerConfig_TLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeSelfSignedCert(t, dir)
+
+	cnf := ServerConfig{TLS: TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: certFile}}
+	tlsCnf, err := cnf.TLSConfig()
+	assert.NoError(t, err)
+	assert.Len(t, tlsCnf.Certificates, 1)
+	assert.NotNil(t, tlsCnf.ClientCAs)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, tlsCnf.ClientAuth)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsCnf.MinVersion)
+
+	cnf.TLS.ClientAuth = TLSClientAuthVerifyIfGiven
+	tlsCnf, err = cnf.TLSConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, tls.VerifyClientCertIfGiven, tlsCnf.ClientAuth)
+
+	cnf.TLS.ClientCAFile = keyFile
+	_, err = cnf.TLSConfig()
+	assert.EqualError(t, err, fmt.Sprintf(tlsClientCAParseErrMsg, keyFile))
+
+	cnf.TLS.ClientCAFile = ""
+	_, err = cnf.TLSConfig()
+	assert.Error(t, err)
+}
@@ -0,0 +1,192 @@
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	invalidErrorDefinitionMsg  = "Invalid error definition '%s' : a code, a 4xx or 5xx status and a message are required"
+	duplicateErrorCodeErrMsg   = "Error code '%s' is already registered"
+	unknownErrorCodeErrMsg     = "Unknown error code '%s'"
+	acceptLanguageWildcard     = "*"
+	acceptLanguageQualityParam = "q="
+)
+
+var (
+	// DefaultCatalog is the catalog used by the package level Register and FromCode functions.
+	DefaultCatalog = NewCatalog()
+
+	messageParamRegexp = regexp.MustCompile(`\{(\w+)\}`)
+)
+
+// ErrorDefinition declares an application error code with its http status, its default message template and
+// the translations of the message template by language tag, for example "de" or "fr-CH".
+// The message templates can contain named parameters in the form "{name}".
+type ErrorDefinition struct {
+	Code         string            `json:"code"`
+	StatusCode   int               `json:"status"`
+	Message      string            `json:"message"`
+	Translations map[string]string `json:"translations,omitempty"`
+}
+
+// Params are the named parameters of an error message template.
+type Params map[string]interface{}
+
+// Catalog is a registry of error definitions.
+type Catalog struct {
+	mu          sync.RWMutex
+	definitions map[string]ErrorDefinition
+}
+
+// NewCatalog returns a new empty Catalog.
+func NewCatalog() *Catalog {
+	return &Catalog{definitions: make(map[string]ErrorDefinition)}
+}
+
+// Register adds copies of the error definitions to the catalog.
+// The method returns an error if a definition is not valid or if its code is already registered, in which case
+// none of the definitions are added.
+func (c *Catalog) Register(defs ...ErrorDefinition) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	codes := make(map[string]bool, len(defs))
+	for _, def := range defs {
+		if def.Code == "" || def.Message == "" || def.StatusCode < http.StatusBadRequest || def.StatusCode >= 600 {
+			return Newf(invalidErrorDefinitionMsg, def.Code)
+		}
+		if _, ok := c.definitions[def.Code]; ok || codes[def.Code] {
+			return Newf(duplicateErrorCodeErrMsg, def.Code)
+		}
+		codes[def.Code] = true
+	}
+	for _, def := range defs {
+		c.definitions[def.Code] = def.clone()
+	}
+	return nil
+}
+
+// Definition returns the error definition of the code.
+func (c *Catalog) Definition(code string) (ErrorDefinition, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	def, ok := c.definitions[code]
+	return def.clone(), ok
+}
+
+// Definitions returns all the error definitions of the catalog sorted by code.
+func (c *Catalog) Definitions() []ErrorDefinition {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	defs := make([]ErrorDefinition, 0, len(c.definitions))
+	for _, def := range c.definitions {
+		defs = append(defs, def.clone())
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].Code < defs[j].Code
+	})
+	return defs
+}
+
+// FromCode returns a RestErr for the error code with the message translated into the preferred language of the
+// Accept-Language header value. The default message is used if there is no translation for the accepted
+// languages. The method returns an internal server RestErr if the code is not registered.
+func (c *Catalog) FromCode(code, acceptLanguage string, params Params) *RestErr {
+	def, ok := c.Definition(code)
+	if !ok {
+		return InternalServerErrorf(unknownErrorCodeErrMsg, code)
+	}
+	msg := formatMessage(def.message(ParseAcceptLanguage(acceptLanguage)), params)
+	return NewRestError(def.StatusCode, msg).WithCode(def.Code)
+}
+
+// Register adds the error definitions to the DefaultCatalog.
+func Register(defs ...ErrorDefinition) error {
+	return DefaultCatalog.Register(defs...)
+}
+
+// FromCode returns a RestErr for the error code from the DefaultCatalog.
+func FromCode(code, acceptLanguage string, params Params) *RestErr {
+	return DefaultCatalog.FromCode(code, acceptLanguage, params)
+}
+
+// clone returns a copy of the error definition that does not share the translations.
+func (def ErrorDefinition) clone() ErrorDefinition {
+	if def.Translations != nil {
+		translations := make(map[string]string, len(def.Translations))
+		for tag, msg := range def.Translations {
+			translations[tag] = msg
+		}
+		def.Translations = translations
+	}
+	return def
+}
+
+// message returns the message template for the first of the languages that has a translation.
+// A language also matches the translation of its base language, for example "de-CH" matches "de".
+func (def ErrorDefinition) message(languages []string) string {
+	for _, lang := range languages {
+		for _, candidate := range []string{lang, strings.SplitN(lang, "-", 2)[0]} {
+			for tag, msg := range def.Translations {
+				if strings.EqualFold(tag, candidate) {
+					return msg
+				}
+			}
+		}
+	}
+	return def.Message
+}
+
+// formatMessage replaces the named parameters of the message template with the parameter values.
+// Parameters that have no value are left unchanged.
+func formatMessage(template string, params Params) string {
+	return messageParamRegexp.ReplaceAllStringFunc(template, func(match string) string {
+		if v, ok := params[match[1:len(match)-1]]; ok {
+			return fmt.Sprint(v)
+		}
+		return match
+	})
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language header value ordered by their quality.
+// The wildcard and the languages with a zero quality are omitted.
+func ParseAcceptLanguage(header string) []string {
+	type language struct {
+		tag     string
+		quality float64
+	}
+	var languages []language
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == acceptLanguageWildcard {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, acceptLanguageQualityParam) {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(param, acceptLanguageQualityParam), 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			languages = append(languages, language{tag: tag, quality: quality})
+		}
+	}
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].quality > languages[j].quality
+	})
+	tags := make([]string, len(languages))
+	for i, l := range languages {
+		tags[i] = l.tag
+	}
+	return tags
+}
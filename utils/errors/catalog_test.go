@@ -0,0 +1,106 @@
+package errors
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+var (
+	userNotFoundDef = ErrorDefinition{
+		Code:       "USER_NOT_FOUND",
+		StatusCode: http.StatusNotFound,
+		Message:    "User '{id}' was not found",
+		Translations: map[string]string{
+			"de":    "Benutzer '{id}' wurde nicht gefunden",
+			"fr-CH": "L'utilisateur '{id}' est introuvable",
+		},
+	}
+	itemConflictDef = ErrorDefinition{
+		Code:       "ITEM_CONFLICT",
+		StatusCode: http.StatusConflict,
+		Message:    "Item already exists",
+	}
+)
+
+func newTestCatalog(t *testing.T) *Catalog {
+	c := NewCatalog()
+	assert.NoError(t, c.Register(userNotFoundDef, itemConflictDef))
+	return c
+}
+
+func TestCatalog_Register(t *testing.T) {
+	c := newTestCatalog(t)
+
+	err := c.Register(userNotFoundDef)
+	assert.EqualError(t, err, fmt.Sprintf(duplicateErrorCodeErrMsg, userNotFoundDef.Code))
+
+	err = c.Register(ErrorDefinition{Code: "A", StatusCode: 400, Message: "a"}, ErrorDefinition{Code: "A", StatusCode: 400, Message: "a"})
+	assert.EqualError(t, err, fmt.Sprintf(duplicateErrorCodeErrMsg, "A"))
+	_, ok := c.Definition("A")
+	assert.False(t, ok)
+
+	err = c.Register(ErrorDefinition{Code: "B", StatusCode: http.StatusOK, Message: "b"})
+	assert.EqualError(t, err, fmt.Sprintf(invalidErrorDefinitionMsg, "B"))
+}
+
+func TestCatalog_Definitions(t *testing.T) {
+	c := newTestCatalog(t)
+	assert.Equal(t, []ErrorDefinition{itemConflictDef, userNotFoundDef}, c.Definitions())
+}
+
+func TestCatalog_CopiesTranslations(t *testing.T) {
+	translations := map[string]string{"de": "Artikel existiert bereits"}
+	c := NewCatalog()
+	assert.NoError(t, c.Register(ErrorDefinition{Code: "A", StatusCode: http.StatusConflict, Message: "a", Translations: translations}))
+
+	// the caller's map is not shared with the catalog
+	translations["de"] = "changed"
+	def, _ := c.Definition("A")
+	assert.Equal(t, "Artikel existiert bereits", def.Translations["de"])
+
+	// the returned maps are not shared with the catalog
+	def.Translations["de"] = "changed"
+	c.Definitions()[0].Translations["de"] = "changed"
+	assert.Equal(t, "Artikel existiert bereits", c.FromCode("A", "de", nil).Message)
+}
+
+func TestCatalog_FromCode(t *testing.T) {
+	c := newTestCatalog(t)
+	params := Params{"id": 42}
+
+	err := c.FromCode(userNotFoundDef.Code, "", params)
+	assert.Equal(t, http.StatusNotFound, err.StatusCode)
+	assert.Equal(t, userNotFoundDef.Code, err.Code)
+	assert.Equal(t, "User '42' was not found", err.Message)
+
+	err = c.FromCode(userNotFoundDef.Code, "de-CH, en;q=0.8", params)
+	assert.Equal(t, "Benutzer '42' wurde nicht gefunden", err.Message)
+
+	err = c.FromCode(userNotFoundDef.Code, "it, fr-ch;q=0.9, de;q=0.5", params)
+	assert.Equal(t, "L'utilisateur '42' est introuvable", err.Message)
+
+	err = c.FromCode(userNotFoundDef.Code, "fr", nil)
+	assert.Equal(t, "User '{id}' was not found", err.Message)
+
+	err = c.FromCode("UNKNOWN", "", nil)
+	assert.Equal(t, http.StatusInternalServerError, err.StatusCode)
+	assert.Equal(t, fmt.Sprintf(unknownErrorCodeErrMsg, "UNKNOWN"), err.Err)
+}
+
+func TestFromCode(t *testing.T) {
+	DefaultCatalog = NewCatalog()
+	defer func() { DefaultCatalog = NewCatalog() }()
+
+	assert.NoError(t, Register(itemConflictDef))
+	err := FromCode(itemConflictDef.Code, "", nil)
+	assert.Equal(t, http.StatusConflict, err.StatusCode)
+	assert.Equal(t, itemConflictDef.Message, err.Message)
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	assert.Equal(t, []string{"fr-CH", "fr", "en", "de"}, ParseAcceptLanguage("fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7, *;q=0.5"))
+	assert.Equal(t, []string{"en"}, ParseAcceptLanguage("de;q=0, en"))
+	assert.Empty(t, ParseAcceptLanguage(""))
+}
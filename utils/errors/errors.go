@@ -9,31 +9,157 @@ import (
 const (
 	MissingMandatoryParamErrMsg = "Missing mandatory parameter(s) : %v"
 	internalServerErrMsg        = "Unable to process the request due to an internal error. Please contact the system administrator"
+	nilRestErrMsg               = "<nil>"
 )
 
-// RestErr represents a REST API error
+// RestErr represents a REST API error.
+// RestErr implements the error interface and can wrap an internal cause. The cause is returned by Error and Unwrap,
+// so it is logged and can be matched with Is and As, but it is never serialised in the API response.
+// Functions that return a *RestErr must not assign a nil *RestErr to an error, the error would not be nil and
+// the RestErr methods are only nil-safe to avoid a panic while logging it.
 type RestErr struct {
-	Message    string `json:"message"`
-	StatusCode int    `json:"status"`
-	Error      string `json:"error"`
+	Message    string                 `json:"message"`
+	StatusCode int                    `json:"status"`
+	Err        string                 `json:"error"`
+	Code       string                 `json:"code,omitempty"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	Fields     []FieldError           `json:"fields,omitempty"`
+	cause      error
 }
 
-// New returns a error.
+// FieldError represents a validation failure of a single field of a request payload.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Error returns the message of the FieldError.
+func (e FieldError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s failed on the '%s' validation", e.Field, e.Rule)
+}
+
+// New returns a error. The error captures a stack trace if the capture is enabled with SetStackTraceEnabled.
 func New(msg string) error {
+	if StackTraceEnabled() {
+		return newTracedError(msg, nil, nil)
+	}
 	return errors.New(msg)
 }
 
-// Newf returns a formatted error.
+// Newf returns a formatted error. The error captures a stack trace if the capture is enabled with
+// SetStackTraceEnabled.
 func Newf(format string, a ...interface{}) error {
+	if StackTraceEnabled() {
+		return newTracedError(fmt.Sprintf(format, a...), nil, nil)
+	}
 	return errors.New(fmt.Sprintf(format, a...))
 }
 
+// Is reports whether any error in err's chain matches target.
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// As finds the first error in err's chain that matches target, and if so, sets target to that error value
+// and returns true.
+func As(err error, target interface{}) bool {
+	return errors.As(err, target)
+}
+
+// Unwrap returns the result of calling the Unwrap method on err, if err's type contains an Unwrap method
+// returning error. Otherwise, Unwrap returns nil.
+func Unwrap(err error) error {
+	return errors.Unwrap(err)
+}
+
+// Error returns the error, the message and the cause of the RestErr.
+func (e *RestErr) Error() string {
+	if e == nil {
+		return nilRestErrMsg
+	}
+	msg := fmt.Sprintf("%s: %s", e.Err, e.Message)
+	if e.Code != "" {
+		msg = fmt.Sprintf("%s [%s]", msg, e.Code)
+	}
+	if e.cause != nil {
+		msg = fmt.Sprintf("%s: %v", msg, e.cause)
+	}
+	return msg
+}
+
+// Unwrap returns the cause of the RestErr.
+func (e *RestErr) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.cause
+}
+
+// Cause returns the cause of the RestErr.
+func (e *RestErr) Cause() error {
+	if e == nil {
+		return nil
+	}
+	return e.cause
+}
+
+// Is checks if the RestErr matches the target. A target RestErr matches if it has the same status code and,
+// when the target has a code, the same code.
+func (e *RestErr) Is(target error) bool {
+	t, ok := target.(*RestErr)
+	if !ok || e == nil || t == nil {
+		return false
+	}
+	return e.StatusCode == t.StatusCode && (t.Code == "" || e.Code == t.Code)
+}
+
+// WithCause sets the internal cause of the RestErr and returns the RestErr.
+func (e *RestErr) WithCause(err error) *RestErr {
+	e.cause = err
+	return e
+}
+
+// WithCode sets the application error code of the RestErr and returns the RestErr.
+func (e *RestErr) WithCode(code string) *RestErr {
+	e.Code = code
+	return e
+}
+
+// WithDetail adds a detail to the RestErr and returns the RestErr.
+func (e *RestErr) WithDetail(key string, value interface{}) *RestErr {
+	if e.Details == nil {
+		e.Details = make(map[string]interface{})
+	}
+	e.Details[key] = value
+	return e
+}
+
+// WithFields adds field validation failures to the RestErr and returns the RestErr.
+func (e *RestErr) WithFields(fields ...FieldError) *RestErr {
+	e.Fields = append(e.Fields, fields...)
+	return e
+}
+
+// NewRestError returns a RestErr for the http status code.
+// Use the status specific constructors when the status code is known.
+func NewRestError(statusCode int, message string) *RestErr {
+	return &RestErr{
+		Message:    message,
+		StatusCode: statusCode,
+		Err:        http.StatusText(statusCode),
+	}
+}
+
 // BadRequestError returns a bad request RestErr.
 func BadRequestError(message string) *RestErr {
 	return &RestErr{
 		Message:    message,
 		StatusCode: http.StatusBadRequest,
-		Error:      http.StatusText(http.StatusBadRequest),
+		Err:        http.StatusText(http.StatusBadRequest),
 	}
 }
 
@@ -42,7 +168,7 @@ func BadRequestErrorf(format string, a ...interface{}) *RestErr {
 	return &RestErr{
 		Message:    fmt.Sprintf(format, a...),
 		StatusCode: http.StatusBadRequest,
-		Error:      http.StatusText(http.StatusBadRequest),
+		Err:        http.StatusText(http.StatusBadRequest),
 	}
 }
 
@@ -51,7 +177,7 @@ func UnauthorizedError(message string) *RestErr {
 	return &RestErr{
 		Message:    message,
 		StatusCode: http.StatusUnauthorized,
-		Error:      http.StatusText(http.StatusUnauthorized),
+		Err:        http.StatusText(http.StatusUnauthorized),
 	}
 }
 
@@ -60,7 +186,7 @@ func UnauthorizedErrorf(format string, a ...interface{}) *RestErr {
 	return &RestErr{
 		Message:    fmt.Sprintf(format, a...),
 		StatusCode: http.StatusUnauthorized,
-		Error:      http.StatusText(http.StatusUnauthorized),
+		Err:        http.StatusText(http.StatusUnauthorized),
 	}
 }
 
@@ -69,7 +195,7 @@ func ForbiddenError(message string) *RestErr {
 	return &RestErr{
 		Message:    message,
 		StatusCode: http.StatusForbidden,
-		Error:      http.StatusText(http.StatusForbidden),
+		Err:        http.StatusText(http.StatusForbidden),
 	}
 }
 
@@ -78,7 +204,7 @@ func ForbiddenErrorf(format string, a ...interface{}) *RestErr {
 	return &RestErr{
 		Message:    fmt.Sprintf(format, a...),
 		StatusCode: http.StatusForbidden,
-		Error:      http.StatusText(http.StatusForbidden),
+		Err:        http.StatusText(http.StatusForbidden),
 	}
 }
 
@@ -87,7 +213,7 @@ func NotFoundError(message string) *RestErr {
 	return &RestErr{
 		Message:    message,
 		StatusCode: http.StatusNotFound,
-		Error:      http.StatusText(http.StatusNotFound),
+		Err:        http.StatusText(http.StatusNotFound),
 	}
 }
 
@@ -96,7 +222,7 @@ func NotFoundErrorf(format string, a ...interface{}) *RestErr {
 	return &RestErr{
 		Message:    fmt.Sprintf(format, a...),
 		StatusCode: http.StatusNotFound,
-		Error:      http.StatusText(http.StatusNotFound),
+		Err:        http.StatusText(http.StatusNotFound),
 	}
 }
 
@@ -105,7 +231,7 @@ func ConflictError(message string) *RestErr {
 	return &RestErr{
 		Message:    message,
 		StatusCode: http.StatusConflict,
-		Error:      http.StatusText(http.StatusConflict),
+		Err:        http.StatusText(http.StatusConflict),
 	}
 }
 
@@ -114,7 +240,133 @@ func ConflictErrorf(format string, a ...interface{}) *RestErr {
 	return &RestErr{
 		Message:    fmt.Sprintf(format, a...),
 		StatusCode: http.StatusConflict,
-		Error:      http.StatusText(http.StatusConflict),
+		Err:        http.StatusText(http.StatusConflict),
+	}
+}
+
+// MethodNotAllowedError returns a method not allowed RestErr.
+func MethodNotAllowedError(message string) *RestErr {
+	return &RestErr{
+		Message:    message,
+		StatusCode: http.StatusMethodNotAllowed,
+		Err:        http.StatusText(http.StatusMethodNotAllowed),
+	}
+}
+
+// MethodNotAllowedErrorf returns a formatted method not allowed RestErr.
+func MethodNotAllowedErrorf(format string, a ...interface{}) *RestErr {
+	return &RestErr{
+		Message:    fmt.Sprintf(format, a...),
+		StatusCode: http.StatusMethodNotAllowed,
+		Err:        http.StatusText(http.StatusMethodNotAllowed),
+	}
+}
+
+// RequestTimeoutError returns a request timeout RestErr.
+func RequestTimeoutError(message string) *RestErr {
+	return &RestErr{
+		Message:    message,
+		StatusCode: http.StatusRequestTimeout,
+		Err:        http.StatusText(http.StatusRequestTimeout),
+	}
+}
+
+// RequestTimeoutErrorf returns a formatted request timeout RestErr.
+func RequestTimeoutErrorf(format string, a ...interface{}) *RestErr {
+	return &RestErr{
+		Message:    fmt.Sprintf(format, a...),
+		StatusCode: http.StatusRequestTimeout,
+		Err:        http.StatusText(http.StatusRequestTimeout),
+	}
+}
+
+// GoneError returns a gone RestErr.
+func GoneError(message string) *RestErr {
+	return &RestErr{
+		Message:    message,
+		StatusCode: http.StatusGone,
+		Err:        http.StatusText(http.StatusGone),
+	}
+}
+
+// GoneErrorf returns a formatted gone RestErr.
+func GoneErrorf(format string, a ...interface{}) *RestErr {
+	return &RestErr{
+		Message:    fmt.Sprintf(format, a...),
+		StatusCode: http.StatusGone,
+		Err:        http.StatusText(http.StatusGone),
+	}
+}
+
+// PreconditionFailedError returns a precondition failed RestErr.
+func PreconditionFailedError(message string) *RestErr {
+	return &RestErr{
+		Message:    message,
+		StatusCode: http.StatusPreconditionFailed,
+		Err:        http.StatusText(http.StatusPreconditionFailed),
+	}
+}
+
+// PreconditionFailedErrorf returns a formatted precondition failed RestErr.
+func PreconditionFailedErrorf(format string, a ...interface{}) *RestErr {
+	return &RestErr{
+		Message:    fmt.Sprintf(format, a...),
+		StatusCode: http.StatusPreconditionFailed,
+		Err:        http.StatusText(http.StatusPreconditionFailed),
+	}
+}
+
+// UnsupportedMediaTypeError returns an unsupported media type RestErr.
+func UnsupportedMediaTypeError(message string) *RestErr {
+	return &RestErr{
+		Message:    message,
+		StatusCode: http.StatusUnsupportedMediaType,
+		Err:        http.StatusText(http.StatusUnsupportedMediaType),
+	}
+}
+
+// UnsupportedMediaTypeErrorf returns a formatted unsupported media type RestErr.
+func UnsupportedMediaTypeErrorf(format string, a ...interface{}) *RestErr {
+	return &RestErr{
+		Message:    fmt.Sprintf(format, a...),
+		StatusCode: http.StatusUnsupportedMediaType,
+		Err:        http.StatusText(http.StatusUnsupportedMediaType),
+	}
+}
+
+// UnprocessableEntityError returns an unprocessable entity RestErr.
+func UnprocessableEntityError(message string) *RestErr {
+	return &RestErr{
+		Message:    message,
+		StatusCode: http.StatusUnprocessableEntity,
+		Err:        http.StatusText(http.StatusUnprocessableEntity),
+	}
+}
+
+// UnprocessableEntityErrorf returns a formatted unprocessable entity RestErr.
+func UnprocessableEntityErrorf(format string, a ...interface{}) *RestErr {
+	return &RestErr{
+		Message:    fmt.Sprintf(format, a...),
+		StatusCode: http.StatusUnprocessableEntity,
+		Err:        http.StatusText(http.StatusUnprocessableEntity),
+	}
+}
+
+// TooManyRequestsError returns a too many requests RestErr.
+func TooManyRequestsError(message string) *RestErr {
+	return &RestErr{
+		Message:    message,
+		StatusCode: http.StatusTooManyRequests,
+		Err:        http.StatusText(http.StatusTooManyRequests),
+	}
+}
+
+// TooManyRequestsErrorf returns a formatted too many requests RestErr.
+func TooManyRequestsErrorf(format string, a ...interface{}) *RestErr {
+	return &RestErr{
+		Message:    fmt.Sprintf(format, a...),
+		StatusCode: http.StatusTooManyRequests,
+		Err:        http.StatusText(http.StatusTooManyRequests),
 	}
 }
 
@@ -123,7 +375,7 @@ func InternalServerError(message string) *RestErr {
 	return &RestErr{
 		Message:    internalServerErrMsg,
 		StatusCode: http.StatusInternalServerError,
-		Error:      message,
+		Err:        message,
 	}
 }
 
@@ -132,6 +384,78 @@ func InternalServerErrorf(format string, a ...interface{}) *RestErr {
 	return &RestErr{
 		Message:    internalServerErrMsg,
 		StatusCode: http.StatusInternalServerError,
-		Error:      fmt.Sprintf(format, a...),
+		Err:        fmt.Sprintf(format, a...),
+	}
+}
+
+// NotImplementedError returns a not implemented RestErr.
+func NotImplementedError(message string) *RestErr {
+	return &RestErr{
+		Message:    message,
+		StatusCode: http.StatusNotImplemented,
+		Err:        http.StatusText(http.StatusNotImplemented),
+	}
+}
+
+// NotImplementedErrorf returns a formatted not implemented RestErr.
+func NotImplementedErrorf(format string, a ...interface{}) *RestErr {
+	return &RestErr{
+		Message:    fmt.Sprintf(format, a...),
+		StatusCode: http.StatusNotImplemented,
+		Err:        http.StatusText(http.StatusNotImplemented),
+	}
+}
+
+// BadGatewayError returns a bad gateway RestErr.
+func BadGatewayError(message string) *RestErr {
+	return &RestErr{
+		Message:    message,
+		StatusCode: http.StatusBadGateway,
+		Err:        http.StatusText(http.StatusBadGateway),
+	}
+}
+
+// BadGatewayErrorf returns a formatted bad gateway RestErr.
+func BadGatewayErrorf(format string, a ...interface{}) *RestErr {
+	return &RestErr{
+		Message:    fmt.Sprintf(format, a...),
+		StatusCode: http.StatusBadGateway,
+		Err:        http.StatusText(http.StatusBadGateway),
+	}
+}
+
+// ServiceUnavailableError returns a service unavailable RestErr.
+func ServiceUnavailableError(message string) *RestErr {
+	return &RestErr{
+		Message:    message,
+		StatusCode: http.StatusServiceUnavailable,
+		Err:        http.StatusText(http.StatusServiceUnavailable),
+	}
+}
+
+// ServiceUnavailableErrorf returns a formatted service unavailable RestErr.
+func ServiceUnavailableErrorf(format string, a ...interface{}) *RestErr {
+	return &RestErr{
+		Message:    fmt.Sprintf(format, a...),
+		StatusCode: http.StatusServiceUnavailable,
+		Err:        http.StatusText(http.StatusServiceUnavailable),
+	}
+}
+
+// GatewayTimeoutError returns a gateway timeout RestErr.
+func GatewayTimeoutError(message string) *RestErr {
+	return &RestErr{
+		Message:    message,
+		StatusCode: http.StatusGatewayTimeout,
+		Err:        http.StatusText(http.StatusGatewayTimeout),
+	}
+}
+
+// GatewayTimeoutErrorf returns a formatted gateway timeout RestErr.
+func GatewayTimeoutErrorf(format string, a ...interface{}) *RestErr {
+	return &RestErr{
+		Message:    fmt.Sprintf(format, a...),
+		StatusCode: http.StatusGatewayTimeout,
+		Err:        http.StatusText(http.StatusGatewayTimeout),
 	}
 }
@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -21,80 +22,244 @@ func TestNewErrorf(t *testing.T) {
 	assert.EqualError(t, Newf(format, arg), fmt.Sprintf(format, arg))
 }
 
+func TestRestErr_Error(t *testing.T) {
+	err := NotFoundError(msg)
+	assert.EqualError(t, err, "Not Found: some message")
+
+	cause := New("some cause")
+	err = NotFoundError(msg).WithCode("USER_NOT_FOUND").WithCause(cause)
+	assert.EqualError(t, err, "Not Found: some message [USER_NOT_FOUND]: some cause")
+	assert.Equal(t, cause, err.Cause())
+}
+
+func TestRestErr_Unwrap(t *testing.T) {
+	cause := New("some cause")
+	var err error = InternalServerError(msg).WithCause(fmt.Errorf("wrapped: %w", cause))
+	assert.True(t, Is(err, cause))
+
+	wrapped := fmt.Errorf("handler: %w", err)
+	restErr := new(RestErr)
+	if assert.True(t, As(wrapped, &restErr)) {
+		assert.Equal(t, http.StatusInternalServerError, restErr.StatusCode)
+	}
+	assert.Equal(t, err, Unwrap(wrapped))
+}
+
+func TestRestErr_Nil(t *testing.T) {
+	var restErr *RestErr
+	assert.Equal(t, "<nil>", restErr.Error())
+	assert.Nil(t, restErr.Unwrap())
+	assert.Nil(t, restErr.Cause())
+	assert.False(t, restErr.Is(NotFoundError("")))
+	assert.False(t, NotFoundError("").Is(restErr))
+
+	// a nil *RestErr assigned to an error is not nil, but it can be logged
+	var err error = restErr
+	assert.True(t, err != nil)
+	assert.Equal(t, "<nil>", fmt.Sprint(err))
+}
+
+func TestRestErr_Is(t *testing.T) {
+	err := NotFoundError(msg).WithCode("USER_NOT_FOUND")
+	assert.True(t, Is(err, NotFoundError("")))
+	assert.True(t, Is(err, NotFoundError("").WithCode("USER_NOT_FOUND")))
+	assert.False(t, Is(err, NotFoundError("").WithCode("ITEM_NOT_FOUND")))
+	assert.False(t, Is(err, ConflictError("")))
+	assert.False(t, Is(err, New(msg)))
+}
+
+func TestRestErr_Json(t *testing.T) {
+	err := NotFoundError(msg).WithCode("USER_NOT_FOUND").WithDetail("id", "1").WithCause(New("secret"))
+	data, jsonErr := json.Marshal(err)
+	assert.NoError(t, jsonErr)
+	assert.Equal(t, `{"message":"some message","status":404,"error":"Not Found","code":"USER_NOT_FOUND","details":{"id":"1"}}`, string(data))
+}
+
+func TestRestErr_WithFields(t *testing.T) {
+	field := FieldError{Field: "name", Rule: "required", Message: "name is required"}
+	err := BadRequestError(msg).WithFields(field)
+	assert.Equal(t, []FieldError{field}, err.Fields)
+}
+
+func TestNewRestError(t *testing.T) {
+	err := NewRestError(http.StatusTeapot, msg)
+	assert.Equal(t, http.StatusTeapot, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusTeapot), err.Err)
+	assert.Equal(t, msg, err.Message)
+}
+
 func TestBadRequestError(t *testing.T) {
 	err := BadRequestError(msg)
 	assert.Equal(t, http.StatusBadRequest, err.StatusCode)
-	assert.Equal(t, http.StatusText(http.StatusBadRequest), err.Error)
+	assert.Equal(t, http.StatusText(http.StatusBadRequest), err.Err)
 	assert.Equal(t, msg, err.Message)
 }
 
 func TestBadRequestErrorf(t *testing.T) {
 	err := BadRequestErrorf(format, arg)
 	assert.Equal(t, http.StatusBadRequest, err.StatusCode)
-	assert.Equal(t, http.StatusText(http.StatusBadRequest), err.Error)
+	assert.Equal(t, http.StatusText(http.StatusBadRequest), err.Err)
 	assert.Equal(t, fmt.Sprintf(format, arg), err.Message)
 }
 
 func TestUnauthorizedError(t *testing.T) {
 	err := UnauthorizedError(msg)
 	assert.Equal(t, http.StatusUnauthorized, err.StatusCode)
-	assert.Equal(t, http.StatusText(http.StatusUnauthorized), err.Error)
+	assert.Equal(t, http.StatusText(http.StatusUnauthorized), err.Err)
 	assert.Equal(t, msg, err.Message)
 }
 
 func TestUnauthorizedErrorf(t *testing.T) {
 	err := UnauthorizedErrorf(format, arg)
 	assert.Equal(t, http.StatusUnauthorized, err.StatusCode)
-	assert.Equal(t, http.StatusText(http.StatusUnauthorized), err.Error)
+	assert.Equal(t, http.StatusText(http.StatusUnauthorized), err.Err)
 	assert.Equal(t, fmt.Sprintf(format, arg), err.Message)
 }
 
 func TestForbiddenError(t *testing.T) {
 	err := ForbiddenError(msg)
 	assert.Equal(t, http.StatusForbidden, err.StatusCode)
-	assert.Equal(t, http.StatusText(http.StatusForbidden), err.Error)
+	assert.Equal(t, http.StatusText(http.StatusForbidden), err.Err)
 	assert.Equal(t, msg, err.Message)
 }
 
 func TestForbiddenErrorf(t *testing.T) {
 	err := ForbiddenErrorf(format, arg)
 	assert.Equal(t, http.StatusForbidden, err.StatusCode)
-	assert.Equal(t, http.StatusText(http.StatusForbidden), err.Error)
+	assert.Equal(t, http.StatusText(http.StatusForbidden), err.Err)
 	assert.Equal(t, fmt.Sprintf(format, arg), err.Message)
 }
 
 func TestNotFoundError(t *testing.T) {
 	err := NotFoundError(msg)
 	assert.Equal(t, http.StatusNotFound, err.StatusCode)
-	assert.Equal(t, http.StatusText(http.StatusNotFound), err.Error)
+	assert.Equal(t, http.StatusText(http.StatusNotFound), err.Err)
 	assert.Equal(t, msg, err.Message)
 }
 
 func TestNotFoundErrorf(t *testing.T) {
 	err := NotFoundErrorf(format, arg)
 	assert.Equal(t, http.StatusNotFound, err.StatusCode)
-	assert.Equal(t, http.StatusText(http.StatusNotFound), err.Error)
+	assert.Equal(t, http.StatusText(http.StatusNotFound), err.Err)
 	assert.Equal(t, fmt.Sprintf(format, arg), err.Message)
 }
 
 func TestConflictError(t *testing.T) {
 	err := ConflictError(msg)
 	assert.Equal(t, http.StatusConflict, err.StatusCode)
-	assert.Equal(t, http.StatusText(http.StatusConflict), err.Error)
+	assert.Equal(t, http.StatusText(http.StatusConflict), err.Err)
 	assert.Equal(t, msg, err.Message)
 }
 
 func TestConflictErrorf(t *testing.T) {
 	err := ConflictErrorf(format, arg)
 	assert.Equal(t, http.StatusConflict, err.StatusCode)
-	assert.Equal(t, http.StatusText(http.StatusConflict), err.Error)
+	assert.Equal(t, http.StatusText(http.StatusConflict), err.Err)
+	assert.Equal(t, fmt.Sprintf(format, arg), err.Message)
+}
+
+func TestMethodNotAllowedError(t *testing.T) {
+	err := MethodNotAllowedError(msg)
+	assert.Equal(t, http.StatusMethodNotAllowed, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusMethodNotAllowed), err.Err)
+	assert.Equal(t, msg, err.Message)
+}
+
+func TestMethodNotAllowedErrorf(t *testing.T) {
+	err := MethodNotAllowedErrorf(format, arg)
+	assert.Equal(t, http.StatusMethodNotAllowed, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusMethodNotAllowed), err.Err)
+	assert.Equal(t, fmt.Sprintf(format, arg), err.Message)
+}
+
+func TestRequestTimeoutError(t *testing.T) {
+	err := RequestTimeoutError(msg)
+	assert.Equal(t, http.StatusRequestTimeout, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusRequestTimeout), err.Err)
+	assert.Equal(t, msg, err.Message)
+}
+
+func TestRequestTimeoutErrorf(t *testing.T) {
+	err := RequestTimeoutErrorf(format, arg)
+	assert.Equal(t, http.StatusRequestTimeout, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusRequestTimeout), err.Err)
+	assert.Equal(t, fmt.Sprintf(format, arg), err.Message)
+}
+
+func TestGoneError(t *testing.T) {
+	err := GoneError(msg)
+	assert.Equal(t, http.StatusGone, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusGone), err.Err)
+	assert.Equal(t, msg, err.Message)
+}
+
+func TestGoneErrorf(t *testing.T) {
+	err := GoneErrorf(format, arg)
+	assert.Equal(t, http.StatusGone, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusGone), err.Err)
+	assert.Equal(t, fmt.Sprintf(format, arg), err.Message)
+}
+
+func TestPreconditionFailedError(t *testing.T) {
+	err := PreconditionFailedError(msg)
+	assert.Equal(t, http.StatusPreconditionFailed, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusPreconditionFailed), err.Err)
+	assert.Equal(t, msg, err.Message)
+}
+
+func TestPreconditionFailedErrorf(t *testing.T) {
+	err := PreconditionFailedErrorf(format, arg)
+	assert.Equal(t, http.StatusPreconditionFailed, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusPreconditionFailed), err.Err)
+	assert.Equal(t, fmt.Sprintf(format, arg), err.Message)
+}
+
+func TestUnsupportedMediaTypeError(t *testing.T) {
+	err := UnsupportedMediaTypeError(msg)
+	assert.Equal(t, http.StatusUnsupportedMediaType, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusUnsupportedMediaType), err.Err)
+	assert.Equal(t, msg, err.Message)
+}
+
+func TestUnsupportedMediaTypeErrorf(t *testing.T) {
+	err := UnsupportedMediaTypeErrorf(format, arg)
+	assert.Equal(t, http.StatusUnsupportedMediaType, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusUnsupportedMediaType), err.Err)
+	assert.Equal(t, fmt.Sprintf(format, arg), err.Message)
+}
+
+func TestUnprocessableEntityError(t *testing.T) {
+	err := UnprocessableEntityError(msg)
+	assert.Equal(t, http.StatusUnprocessableEntity, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusUnprocessableEntity), err.Err)
+	assert.Equal(t, msg, err.Message)
+}
+
+func TestUnprocessableEntityErrorf(t *testing.T) {
+	err := UnprocessableEntityErrorf(format, arg)
+	assert.Equal(t, http.StatusUnprocessableEntity, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusUnprocessableEntity), err.Err)
+	assert.Equal(t, fmt.Sprintf(format, arg), err.Message)
+}
+
+func TestTooManyRequestsError(t *testing.T) {
+	err := TooManyRequestsError(msg)
+	assert.Equal(t, http.StatusTooManyRequests, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusTooManyRequests), err.Err)
+	assert.Equal(t, msg, err.Message)
+}
+
+func TestTooManyRequestsErrorf(t *testing.T) {
+	err := TooManyRequestsErrorf(format, arg)
+	assert.Equal(t, http.StatusTooManyRequests, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusTooManyRequests), err.Err)
 	assert.Equal(t, fmt.Sprintf(format, arg), err.Message)
 }
 
 func TestInternalServerError(t *testing.T) {
 	err := InternalServerError(msg)
 	assert.Equal(t, http.StatusInternalServerError, err.StatusCode)
-	assert.Equal(t, msg, err.Error)
+	assert.Equal(t, msg, err.Err)
 	assert.Equal(t, internalServerErrMsg, err.Message)
 }
 
@@ -102,5 +267,61 @@ func TestInternalServerErrorf(t *testing.T) {
 	err := InternalServerErrorf(format, arg)
 	assert.Equal(t, http.StatusInternalServerError, err.StatusCode)
 	assert.Equal(t, internalServerErrMsg, err.Message)
-	assert.Equal(t, fmt.Sprintf(format, arg), err.Error)
+	assert.Equal(t, fmt.Sprintf(format, arg), err.Err)
+}
+
+func TestNotImplementedError(t *testing.T) {
+	err := NotImplementedError(msg)
+	assert.Equal(t, http.StatusNotImplemented, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusNotImplemented), err.Err)
+	assert.Equal(t, msg, err.Message)
+}
+
+func TestNotImplementedErrorf(t *testing.T) {
+	err := NotImplementedErrorf(format, arg)
+	assert.Equal(t, http.StatusNotImplemented, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusNotImplemented), err.Err)
+	assert.Equal(t, fmt.Sprintf(format, arg), err.Message)
+}
+
+func TestBadGatewayError(t *testing.T) {
+	err := BadGatewayError(msg)
+	assert.Equal(t, http.StatusBadGateway, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusBadGateway), err.Err)
+	assert.Equal(t, msg, err.Message)
+}
+
+func TestBadGatewayErrorf(t *testing.T) {
+	err := BadGatewayErrorf(format, arg)
+	assert.Equal(t, http.StatusBadGateway, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusBadGateway), err.Err)
+	assert.Equal(t, fmt.Sprintf(format, arg), err.Message)
+}
+
+func TestServiceUnavailableError(t *testing.T) {
+	err := ServiceUnavailableError(msg)
+	assert.Equal(t, http.StatusServiceUnavailable, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusServiceUnavailable), err.Err)
+	assert.Equal(t, msg, err.Message)
+}
+
+func TestServiceUnavailableErrorf(t *testing.T) {
+	err := ServiceUnavailableErrorf(format, arg)
+	assert.Equal(t, http.StatusServiceUnavailable, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusServiceUnavailable), err.Err)
+	assert.Equal(t, fmt.Sprintf(format, arg), err.Message)
+}
+
+func TestGatewayTimeoutError(t *testing.T) {
+	err := GatewayTimeoutError(msg)
+	assert.Equal(t, http.StatusGatewayTimeout, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusGatewayTimeout), err.Err)
+	assert.Equal(t, msg, err.Message)
+}
+
+func TestGatewayTimeoutErrorf(t *testing.T) {
+	err := GatewayTimeoutErrorf(format, arg)
+	assert.Equal(t, http.StatusGatewayTimeout, err.StatusCode)
+	assert.Equal(t, http.StatusText(http.StatusGatewayTimeout), err.Err)
+	assert.Equal(t, fmt.Sprintf(format, arg), err.Message)
 }
@@ -0,0 +1,129 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	multiErrorHeaderMsg = "%d errors occurred:"
+	multiErrorsKey      = "errors"
+)
+
+// MultiError collects multiple errors into a single error.
+// Is and As match the errors of any of the members.
+type MultiError struct {
+	Errors []error
+}
+
+// NewMultiError returns a new MultiError with the errors that are not nil.
+func NewMultiError(errs ...error) *MultiError {
+	return new(MultiError).Append(errs...)
+}
+
+// Append adds the errors that are not nil to the MultiError and returns the MultiError.
+// The members of an appended MultiError are added instead of the MultiError itself.
+func (m *MultiError) Append(errs ...error) *MultiError {
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if multi, ok := err.(*MultiError); ok {
+			m.Errors = append(m.Errors, multi.Errors...)
+			continue
+		}
+		m.Errors = append(m.Errors, err)
+	}
+	return m
+}
+
+// Len returns the number of errors in the MultiError.
+func (m *MultiError) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.Errors)
+}
+
+// ErrorOrNil returns the MultiError if it contains errors and nil otherwise.
+func (m *MultiError) ErrorOrNil() error {
+	if m.Len() == 0 {
+		return nil
+	}
+	return m
+}
+
+// Error returns the errors as a readable list. If there is a single error its message is returned as is.
+func (m *MultiError) Error() string {
+	if m.Len() == 1 {
+		return m.Errors[0].Error()
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, multiErrorHeaderMsg, m.Len())
+	for _, err := range m.Errors {
+		fmt.Fprintf(&sb, "\n\t* %s", err)
+	}
+	return sb.String()
+}
+
+// Unwrap returns the errors of the MultiError.
+func (m *MultiError) Unwrap() []error {
+	return m.Errors
+}
+
+// Is checks if any of the errors matches the target.
+func (m *MultiError) Is(target error) bool {
+	for _, err := range m.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors that matches the target, and if so, sets target to that error value.
+func (m *MultiError) As(target interface{}) bool {
+	for _, err := range m.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON encodes the errors as a json array. FieldError and RestErr members are encoded as they are and the
+// other errors as objects with their message.
+func (m *MultiError) MarshalJSON() ([]byte, error) {
+	items := make([]interface{}, len(m.Errors))
+	for i, err := range m.Errors {
+		switch e := err.(type) {
+		case FieldError, *FieldError, *RestErr:
+			items[i] = e
+		default:
+			items[i] = map[string]string{"message": err.Error()}
+		}
+	}
+	return json.Marshal(items)
+}
+
+// RestErr converts the MultiError into a RestErr with the status code, usually 400 or 422.
+// The FieldError members are added as the fields of the RestErr and the messages of the other members as the
+// "errors" detail. The MultiError is the cause of the RestErr.
+func (m *MultiError) RestErr(statusCode int, message string) *RestErr {
+	restErr := NewRestError(statusCode, message).WithCause(m)
+	var msgs []string
+	for _, err := range m.Errors {
+		var fe FieldError
+		if errors.As(err, &fe) {
+			restErr.WithFields(fe)
+		} else {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	if len(msgs) > 0 {
+		restErr.WithDetail(multiErrorsKey, msgs)
+	}
+	return restErr
+}
@@ -0,0 +1,66 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestMultiError_Append(t *testing.T) {
+	m := NewMultiError(nil, New("a"))
+	m.Append(NewMultiError(New("b"), New("c")), nil)
+	assert.Equal(t, 3, m.Len())
+
+	var empty *MultiError
+	assert.Equal(t, 0, empty.Len())
+	assert.Nil(t, empty.ErrorOrNil())
+	assert.Nil(t, NewMultiError().ErrorOrNil())
+	assert.Equal(t, m, m.ErrorOrNil())
+}
+
+func TestMultiError_Error(t *testing.T) {
+	assert.EqualError(t, NewMultiError(New("a")), "a")
+	assert.EqualError(t, NewMultiError(New("a"), New("b")), "2 errors occurred:\n\t* a\n\t* b")
+}
+
+func TestMultiError_IsAs(t *testing.T) {
+	sentinel := New("sentinel")
+	var err error = NewMultiError(New("a"), fmt.Errorf("wrapped: %w", sentinel), NotFoundError(msg))
+
+	assert.True(t, Is(err, sentinel))
+	assert.True(t, Is(err, NotFoundError("")))
+	assert.False(t, Is(err, New("sentinel")))
+
+	restErr := new(RestErr)
+	if assert.True(t, As(err, &restErr)) {
+		assert.Equal(t, http.StatusNotFound, restErr.StatusCode)
+	}
+	var fe FieldError
+	assert.False(t, As(err, &fe))
+}
+
+func TestMultiError_MarshalJSON(t *testing.T) {
+	m := NewMultiError(FieldError{Field: "name", Rule: "required", Message: "name is required"}, New("a"))
+	data, err := json.Marshal(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"field":"name","rule":"required","message":"name is required"},{"message":"a"}]`, string(data))
+}
+
+func TestMultiError_RestErr(t *testing.T) {
+	field := FieldError{Field: "name", Rule: "required"}
+	m := NewMultiError(field, New("a"))
+	err := m.RestErr(http.StatusUnprocessableEntity, msg)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, err.StatusCode)
+	assert.Equal(t, msg, err.Message)
+	assert.Equal(t, []FieldError{field}, err.Fields)
+	assert.Equal(t, []string{"a"}, err.Details[multiErrorsKey])
+	assert.Equal(t, m, err.Cause())
+}
+
+func TestFieldError_Error(t *testing.T) {
+	assert.EqualError(t, FieldError{Field: "name", Rule: "required", Message: "name is required"}, "name is required")
+	assert.EqualError(t, FieldError{Field: "name", Rule: "required"}, "name failed on the 'required' validation")
+}
@@ -0,0 +1,98 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	ProblemJsonMIMEType = "application/problem+json"
+	DefaultProblemType  = "about:blank"
+	problemCodeKey      = "code"
+	problemDetailsKey   = "details"
+	problemFieldsKey    = "fields"
+)
+
+var (
+	problemMembers = []string{"type", "title", "status", "detail", "instance"}
+)
+
+// Problem represents an RFC 7807 problem details object.
+// Extensions are serialised as additional members of the problem object.
+type Problem struct {
+	Type       string                 `json:"type,omitempty"`
+	Title      string                 `json:"title,omitempty"`
+	Status     int                    `json:"status,omitempty"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// problem is used to marshal the standard members of a Problem without recursion.
+type problem Problem
+
+// Problem returns the RFC 7807 problem details of the RestErr.
+// The instance is the URI reference of the occurrence of the problem, usually the request path.
+// The code, the details and the fields of the RestErr are added as extension members. The error of an internal
+// server RestErr and the cause of the RestErr are not included in the problem details.
+func (e *RestErr) Problem(instance string) *Problem {
+	p := &Problem{
+		Type:     DefaultProblemType,
+		Title:    http.StatusText(e.StatusCode),
+		Status:   e.StatusCode,
+		Detail:   e.Message,
+		Instance: instance,
+	}
+	extensions := make(map[string]interface{})
+	if e.Code != "" {
+		extensions[problemCodeKey] = e.Code
+	}
+	if len(e.Details) > 0 {
+		extensions[problemDetailsKey] = e.Details
+	}
+	if len(e.Fields) > 0 {
+		extensions[problemFieldsKey] = e.Fields
+	}
+	if len(extensions) > 0 {
+		p.Extensions = extensions
+	}
+	return p
+}
+
+// MarshalJSON encodes the problem and its extension members into json.
+// Extension members cannot override the standard members.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+	members := make(map[string]interface{}, len(p.Extensions)+len(problemMembers))
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	return json.Marshal(members)
+}
+
+// UnmarshalJSON decodes a problem from json. The members that are not standard members are decoded into the
+// extensions of the problem.
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	var std problem
+	if err := json.Unmarshal(data, &std); err != nil {
+		return err
+	}
+	members := make(map[string]interface{})
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	for _, k := range problemMembers {
+		delete(members, k)
+	}
+	*p = Problem(std)
+	if len(members) > 0 {
+		p.Extensions = members
+	}
+	return nil
+}
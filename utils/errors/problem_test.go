@@ -0,0 +1,59 @@
+package errors
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestRestErr_Problem(t *testing.T) {
+	p := NotFoundError(msg).Problem("/users/1")
+	assert.Equal(t, DefaultProblemType, p.Type)
+	assert.Equal(t, http.StatusText(http.StatusNotFound), p.Title)
+	assert.Equal(t, http.StatusNotFound, p.Status)
+	assert.Equal(t, msg, p.Detail)
+	assert.Equal(t, "/users/1", p.Instance)
+	assert.Nil(t, p.Extensions)
+
+	p = InternalServerError(msg).Problem("")
+	assert.Equal(t, internalServerErrMsg, p.Detail)
+
+	field := FieldError{Field: "name", Rule: "required", Message: "name is required"}
+	p = BadRequestError(msg).WithFields(field).Problem("")
+	assert.Equal(t, []FieldError{field}, p.Extensions[problemFieldsKey])
+
+	p = NotFoundError(msg).WithCode("USER_NOT_FOUND").WithDetail("id", "1").WithCause(New("secret")).Problem("")
+	assert.Equal(t, map[string]interface{}{
+		problemCodeKey:    "USER_NOT_FOUND",
+		problemDetailsKey: map[string]interface{}{"id": "1"},
+	}, p.Extensions)
+}
+
+func TestProblem_MarshalJSON(t *testing.T) {
+	p := Problem{
+		Type:       DefaultProblemType,
+		Title:      "Not Found",
+		Status:     http.StatusNotFound,
+		Detail:     msg,
+		Extensions: map[string]interface{}{"code": "USER_NOT_FOUND", "status": 200},
+	}
+	data, err := json.Marshal(p)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"about:blank","title":"Not Found","status":404,"detail":"some message","code":"USER_NOT_FOUND"}`, string(data))
+
+	data, err = json.Marshal(Problem{Title: "Not Found"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"title":"Not Found"}`, string(data))
+}
+
+func TestProblem_UnmarshalJSON(t *testing.T) {
+	p := new(Problem)
+	err := json.Unmarshal([]byte(`{"type":"about:blank","title":"Not Found","status":404,"code":"USER_NOT_FOUND"}`), p)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, p.Status)
+	assert.Equal(t, "Not Found", p.Title)
+	assert.Equal(t, map[string]interface{}{"code": "USER_NOT_FOUND"}, p.Extensions)
+
+	assert.Error(t, json.Unmarshal([]byte(`[]`), p))
+}
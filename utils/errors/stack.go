@@ -0,0 +1,147 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	maxStackDepth = 32
+)
+
+var (
+	stackTraceEnabled int32
+)
+
+// tracedError is an error with an optional cause, stack trace and key/value context.
+type tracedError struct {
+	msg     string
+	cause   error
+	stack   []uintptr
+	context map[string]interface{}
+}
+
+// SetStackTraceEnabled enables or disables the capture of stack traces by New, Newf, Wrap and WithContext.
+// The capture is disabled by default.
+func SetStackTraceEnabled(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&stackTraceEnabled, v)
+}
+
+// StackTraceEnabled reports whether stack traces are captured.
+func StackTraceEnabled() bool {
+	return atomic.LoadInt32(&stackTraceEnabled) == 1
+}
+
+// Wrap returns an error with the message that wraps err, or nil if err is nil.
+// The key/value pairs are added to the context of the error, for example Wrap(err, "read failed", "path", p).
+// The error message is "<msg>: <err>" or the message of err if msg is empty.
+func Wrap(err error, msg string, keysAndValues ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return newTracedError(msg, err, keysAndValues)
+}
+
+// WithContext returns an error that wraps err with the key/value pairs added to its context, or nil if err is nil.
+// The message of the returned error is the message of err.
+func WithContext(err error, keysAndValues ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return newTracedError("", err, keysAndValues)
+}
+
+// StackTrace returns the stack trace captured by the innermost error of err's chain that has one.
+// The method returns "" if no stack trace was captured.
+func StackTrace(err error) string {
+	var stack []uintptr
+	for ; err != nil; err = Unwrap(err) {
+		if e, ok := err.(*tracedError); ok && len(e.stack) > 0 {
+			stack = e.stack
+		}
+	}
+	return formatStack(stack)
+}
+
+// Context returns the key/value context of all the errors of err's chain.
+// When a key is set more than once the value of the outermost error is returned.
+func Context(err error) map[string]interface{} {
+	var ctx map[string]interface{}
+	for ; err != nil; err = Unwrap(err) {
+		e, ok := err.(*tracedError)
+		if !ok {
+			continue
+		}
+		for k, v := range e.context {
+			if ctx == nil {
+				ctx = make(map[string]interface{})
+			}
+			if _, ok := ctx[k]; !ok {
+				ctx[k] = v
+			}
+		}
+	}
+	return ctx
+}
+
+// Error returns the message and the cause of the error.
+func (e *tracedError) Error() string {
+	switch {
+	case e.cause == nil:
+		return e.msg
+	case e.msg == "":
+		return e.cause.Error()
+	default:
+		return fmt.Sprintf("%s: %v", e.msg, e.cause)
+	}
+}
+
+// Unwrap returns the cause of the error.
+func (e *tracedError) Unwrap() error {
+	return e.cause
+}
+
+// newTracedError returns a tracedError. The stack trace starts at the caller of the exported function.
+func newTracedError(msg string, cause error, keysAndValues []interface{}) *tracedError {
+	e := &tracedError{msg: msg, cause: cause}
+	if StackTraceEnabled() {
+		pcs := make([]uintptr, maxStackDepth)
+		e.stack = pcs[:runtime.Callers(3, pcs)]
+	}
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if e.context == nil {
+			e.context = make(map[string]interface{})
+		}
+		key := fmt.Sprint(keysAndValues[i])
+		if i+1 < len(keysAndValues) {
+			e.context[key] = keysAndValues[i+1]
+		} else {
+			e.context[key] = nil
+		}
+	}
+	return e
+}
+
+// formatStack formats the program counters as one "function\n\tfile:line" entry per frame.
+func formatStack(stack []uintptr) string {
+	if len(stack) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	frames := runtime.CallersFrames(stack)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&sb, "%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
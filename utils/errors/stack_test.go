@@ -0,0 +1,48 @@
+package errors
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestNew_StackTrace(t *testing.T) {
+	assert.Equal(t, "", StackTrace(New(msg)))
+
+	SetStackTraceEnabled(true)
+	defer SetStackTraceEnabled(false)
+	assert.True(t, StackTraceEnabled())
+
+	err := Newf("%s", msg)
+	assert.EqualError(t, err, msg)
+	stack := StackTrace(err)
+	assert.True(t, strings.HasPrefix(stack, "github.com/privatesquare/bkst-go-utils/utils/errors.TestNew_StackTrace"))
+	assert.Contains(t, stack, "stack_test.go")
+}
+
+func TestWrap(t *testing.T) {
+	assert.Nil(t, Wrap(nil, msg))
+	assert.Nil(t, WithContext(nil, "key", "value"))
+
+	err := Wrap(fs.ErrNotExist, msg, "path", "/tmp/file", "mode")
+	assert.EqualError(t, err, msg+": "+fs.ErrNotExist.Error())
+	assert.True(t, Is(err, fs.ErrNotExist))
+	assert.Equal(t, map[string]interface{}{"path": "/tmp/file", "mode": nil}, Context(err))
+	assert.Equal(t, "", StackTrace(err))
+
+	err = WithContext(err, "path", "/other", "user", "admin")
+	assert.EqualError(t, err, msg+": "+fs.ErrNotExist.Error())
+	assert.Equal(t, map[string]interface{}{"path": "/other", "mode": nil, "user": "admin"}, Context(err))
+	assert.Nil(t, Context(New(msg)))
+}
+
+func TestWrap_StackTrace(t *testing.T) {
+	SetStackTraceEnabled(true)
+	defer SetStackTraceEnabled(false)
+
+	inner := New(msg)
+	err := Wrap(inner, "outer")
+	assert.Equal(t, StackTrace(inner), StackTrace(err))
+	assert.Contains(t, StackTrace(Wrap(fs.ErrNotExist, "outer")), "TestWrap_StackTrace")
+}
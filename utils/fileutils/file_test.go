@@ -1,7 +1,10 @@
 package fileutils
 
 import (
+	"fmt"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
 	"github.com/stretchr/testify/assert"
+	"io/fs"
 	"testing"
 )
 
@@ -37,6 +40,10 @@ func TestOpenFile(t *testing.T) {
 	t.Run("error", func(t *testing.T) {
 		_, err := OpenFile(invalidFilePath)
 		assert.Error(t, err)
+		assert.True(t, errors.Is(err, fs.ErrNotExist))
+		notFoundErr := FileNotFoundError{}
+		assert.True(t, errors.As(err, &notFoundErr))
+		assert.Equal(t, invalidFilePath, notFoundErr.Path)
 	})
 }
 
@@ -67,7 +74,19 @@ func TestReadFile(t *testing.T) {
 
 	t.Run("invalid", func(t *testing.T) {
 		_, err := ReadFile(invalidFilePath)
+		assert.EqualError(t, err, fmt.Sprintf(fileNotFoundErrMsg, invalidFilePath))
+		assert.True(t, errors.Is(err, fs.ErrNotExist))
+		pathErr := new(fs.PathError)
+		assert.True(t, errors.As(err, &pathErr))
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		_, err := ReadFile("test")
 		assert.Error(t, err)
+		assert.False(t, errors.Is(err, fs.ErrNotExist))
+		readErr := FileReadError{}
+		assert.True(t, errors.As(err, &readErr))
+		assert.Equal(t, "test", readErr.Path)
 	})
 }
 
@@ -87,6 +106,21 @@ func TestReadJsonFile(t *testing.T) {
 	})
 }
 
+func TestFileNotFoundError(t *testing.T) {
+	err := FileNotFoundError{Path: invalidFilePath}
+	assert.EqualError(t, err, fmt.Sprintf(fileNotFoundErrMsg, invalidFilePath))
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+}
+
+func TestCreateFile_Error(t *testing.T) {
+	filePath := "test/missing/test.txt"
+	_, err := CreateFile(filePath)
+	createErr := FileCreateError{}
+	assert.True(t, errors.As(err, &createErr))
+	assert.Equal(t, filePath, createErr.Path)
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+}
+
 func TestWriteFile(t *testing.T) {
 	filePath := "test/test.txt"
 	err := WriteFile(filePath, []byte("something"))
@@ -2,7 +2,9 @@ package fileutils
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"io/fs"
 	"io/ioutil"
 	"os"
 )
@@ -14,8 +16,97 @@ const (
 	fileOpenErrMsg     = "Unable to open file '%s' : %v"
 	fileReadErrMsg     = "Unable to read file '%s' : %v"
 	fileWriteErrMsg    = "Unable to write to the file '%s' : %v"
+	fileRemoveErrMsg   = "Unable to remove file '%s' : %v"
 )
 
+// FileNotFoundError is returned when a file does not exist. Err is the underlying OS error, if any.
+type FileNotFoundError struct {
+	Path string
+	Err  error
+}
+
+func (e FileNotFoundError) Error() string {
+	return fmt.Sprintf(fileNotFoundErrMsg, e.Path)
+}
+
+// Unwrap returns the underlying error. If there is none fs.ErrNotExist is returned.
+func (e FileNotFoundError) Unwrap() error {
+	if e.Err == nil {
+		return fs.ErrNotExist
+	}
+	return e.Err
+}
+
+// FileOpenError is returned when a file cannot be opened.
+type FileOpenError struct {
+	Path string
+	Err  error
+}
+
+func (e FileOpenError) Error() string {
+	return fmt.Sprintf(fileOpenErrMsg, e.Path, e.Err)
+}
+
+func (e FileOpenError) Unwrap() error {
+	return e.Err
+}
+
+// FileCreateError is returned when a file cannot be created.
+type FileCreateError struct {
+	Path string
+	Err  error
+}
+
+func (e FileCreateError) Error() string {
+	return fmt.Sprintf(fileCreateErrMsg, e.Path, e.Err)
+}
+
+func (e FileCreateError) Unwrap() error {
+	return e.Err
+}
+
+// FileReadError is returned when the contents of a file cannot be read.
+type FileReadError struct {
+	Path string
+	Err  error
+}
+
+func (e FileReadError) Error() string {
+	return fmt.Sprintf(fileReadErrMsg, e.Path, e.Err)
+}
+
+func (e FileReadError) Unwrap() error {
+	return e.Err
+}
+
+// FileWriteError is returned when data cannot be written to a file.
+type FileWriteError struct {
+	Path string
+	Err  error
+}
+
+func (e FileWriteError) Error() string {
+	return fmt.Sprintf(fileWriteErrMsg, e.Path, e.Err)
+}
+
+func (e FileWriteError) Unwrap() error {
+	return e.Err
+}
+
+// FileRemoveError is returned when a file cannot be removed.
+type FileRemoveError struct {
+	Path string
+	Err  error
+}
+
+func (e FileRemoveError) Error() string {
+	return fmt.Sprintf(fileRemoveErrMsg, e.Path, e.Err)
+}
+
+func (e FileRemoveError) Unwrap() error {
+	return e.Err
+}
+
 // FileExists checks if a file exists and returns an error if the file was not found
 func FileExists(filePath string) bool {
 	if _, err := os.Stat(filePath); err != nil {
@@ -25,41 +116,44 @@ func FileExists(filePath string) bool {
 }
 
 // OpenFile opens a file
-// The method returns an error if there is an issue with opening the file
+// The method returns a FileNotFoundError if the file does not exist
+// or a FileOpenError if there is another issue with opening the file
 func OpenFile(file string) (*os.File, error) {
 	f, err := os.Open(file)
-	if err != nil {
-		return f, errors.Newf(fileOpenErrMsg, file, err)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, FileNotFoundError{Path: file, Err: err}
+	} else if err != nil {
+		return f, FileOpenError{Path: file, Err: err}
 	}
 	return f, nil
 }
 
 // CreateFile creates a new file
-// The method returns an error if there was an issue with creating an new file
+// The method returns a FileCreateError if there was an issue with creating an new file
 func CreateFile(file string) (*os.File, error) {
 	f, err := os.Create(file)
 	if err != nil {
-		return f, errors.Newf(fileCreateErrMsg, file, err)
+		return f, FileCreateError{Path: file, Err: err}
 	}
 	return f, nil
 }
 
-// ReadFile checks if a file exists and if it does try to read the contents of the
-// file and returns the data back
-// The method returns an error the file does not exist or if there was an error in reading the contents of the file
+// ReadFile reads the contents of the file and returns the data back
+// The method returns a FileNotFoundError if the file does not exist
+// or a FileReadError if there was an error in reading the contents of the file
 func ReadFile(file string) ([]byte, error) {
-	if !FileExists(file) {
-		return nil, errors.Newf(fileNotFoundErrMsg, file)
-	} else if data, err := ioutil.ReadFile(file); err != nil {
-		return nil, errors.Newf(fileReadErrMsg, file, err)
-	} else {
-		return data, err
+	data, err := ioutil.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, FileNotFoundError{Path: file, Err: err}
+	} else if err != nil {
+		return nil, FileReadError{Path: file, Err: err}
 	}
+	return data, nil
 }
 
 // WriteFile creates a new file if the file does not exists and writes data into the file
-// The method returns an error if there was an issue creating a new file
-// or while writing data into the file
+// The method returns a FileCreateError if there was an issue creating a new file
+// or a FileWriteError if there was an issue while writing data into the file
 func WriteFile(file string, data []byte) error {
 	var (
 		err error
@@ -72,16 +166,17 @@ func WriteFile(file string, data []byte) error {
 	}
 	err = ioutil.WriteFile(file, data, 0644)
 	if err != nil {
-		return errors.Newf(fileWriteErrMsg, file, err)
+		return FileWriteError{Path: file, Err: err}
 	}
 	return nil
 }
 
 // RemoveFile removes files from the provided valid filePath.
+// The method returns a FileRemoveError if the file exists and cannot be removed.
 func RemoveFile(filePath string) error {
 	if FileExists(filePath) {
 		if err := os.Remove(filePath); err != nil {
-			return err
+			return FileRemoveError{Path: filePath, Err: err}
 		}
 	}
 	return nil
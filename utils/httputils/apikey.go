@@ -0,0 +1,170 @@
+package httputils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/privatesquare/bkst-go-utils/utils/fileutils"
+	"github.com/privatesquare/bkst-go-utils/utils/logger"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	APIKeyHeaderKey      = "X-Api-Key"
+	APIKeyRequiredErrMsg = "401 unauthorized: API key is required"
+	APIKeyInvalidErrMsg  = "401 unauthorized: API key is not valid"
+	apiKeyStoreErrMsg    = "Unable to look up the API key"
+	invalidAPIKeyErrMsg  = "Invalid API key : a client and a sha256 hash are required"
+	nilAPIKeyStoreErrMsg = "API key store must not be nil"
+	apiKeyLength         = 32
+)
+
+// APIKey is an API key of a client. Only the hex encoded sha256 hash of the key is stored.
+// A client can have multiple keys, so that a new key can be issued before the old key expires.
+type APIKey struct {
+	Client    string    `json:"client"`
+	Hash      string    `json:"hash"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// APIKeyStore looks up API keys by hash.
+type APIKeyStore interface {
+	// Lookup returns the API key with the hash, or nil if the key is unknown.
+	Lookup(hash string) (*APIKey, error)
+}
+
+// APIKeyConfig represents the configuration of the API key authentication middleware.
+type APIKeyConfig struct {
+	// Header is the request header of the API key. Defaults to APIKeyHeaderKey.
+	Header string
+	// QueryParam is the query parameter of the API key if the key is not set in the header.
+	// Keys are not accepted as query parameters if it is empty. The value of the parameter is removed from the
+	// request log of logger.GinZap, but the proxies and the access logs in front of the service still record the
+	// keys in plaintext, prefer the header.
+	QueryParam string
+	// Store holds the API keys. The store is required.
+	Store APIKeyStore
+}
+
+// MemoryAPIKeyStore is an APIKeyStore that keeps the API keys in memory.
+type MemoryAPIKeyStore struct {
+	mu   sync.RWMutex
+	keys map[string]APIKey
+}
+
+// NewMemoryAPIKeyStore returns a MemoryAPIKeyStore with the API keys.
+// The method returns an error if a key has no client or is not a sha256 hash.
+func NewMemoryAPIKeyStore(keys ...APIKey) (*MemoryAPIKeyStore, error) {
+	s := &MemoryAPIKeyStore{keys: make(map[string]APIKey)}
+	if err := s.Add(keys...); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// LoadAPIKeyStore reads a json file with a list of API keys into a MemoryAPIKeyStore, for example
+// [{"client": "billing", "hash": "<sha256 hex>", "expires_at": "2021-12-31T00:00:00Z"}].
+func LoadAPIKeyStore(filePath string) (*MemoryAPIKeyStore, error) {
+	var keys []APIKey
+	if err := fileutils.ReadJsonFile(filePath, &keys); err != nil {
+		return nil, err
+	}
+	return NewMemoryAPIKeyStore(keys...)
+}
+
+// Add adds API keys to the store.
+func (s *MemoryAPIKeyStore) Add(keys ...APIKey) error {
+	for _, key := range keys {
+		if b, err := hex.DecodeString(key.Hash); key.Client == "" || err != nil || len(b) != sha256.Size {
+			return errors.New(invalidAPIKeyErrMsg)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		s.keys[strings.ToLower(key.Hash)] = key
+	}
+	return nil
+}
+
+// Remove removes the API key with the hash from the store.
+func (s *MemoryAPIKeyStore) Remove(hash string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, strings.ToLower(hash))
+}
+
+// Lookup returns the API key with the hash.
+func (s *MemoryAPIKeyStore) Lookup(hash string) (*APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok := s.keys[strings.ToLower(hash)]
+	if !ok {
+		return nil, nil
+	}
+	return &key, nil
+}
+
+// GenerateAPIKey returns a new random API key and its hash.
+func GenerateAPIKey() (string, string, error) {
+	b := make([]byte, apiKeyLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	key := base64.RawURLEncoding.EncodeToString(b)
+	return key, HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the hex encoded sha256 hash of the API key.
+func HashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// APIKeyAuth is a gin middleware for validating the API key of the request.
+// The method writes a Principal with the client of the API key to the gin context.
+// The method returns an error if the API key is not set, is unknown or has expired.
+// The method panics if the store of the configuration is nil.
+func APIKeyAuth(cnf APIKeyConfig) gin.HandlerFunc {
+	if cnf.Store == nil {
+		panic(nilAPIKeyStoreErrMsg)
+	}
+	if cnf.Header == "" {
+		cnf.Header = APIKeyHeaderKey
+	}
+	return func(ctx *gin.Context) {
+		if cnf.QueryParam != "" {
+			logger.RedactQueryParam(ctx, cnf.QueryParam)
+		}
+		key := ctx.GetHeader(cnf.Header)
+		if key == "" && cnf.QueryParam != "" {
+			key = ctx.Query(cnf.QueryParam)
+		}
+		if key == "" {
+			apiKeyAuthError(ctx, APIKeyRequiredErrMsg)
+			return
+		}
+		apiKey, err := cnf.Store.Lookup(HashAPIKey(key))
+		if err != nil {
+			logger.Error(apiKeyStoreErrMsg, err)
+		}
+		if apiKey == nil || (!apiKey.ExpiresAt.IsZero() && !time.Now().Before(apiKey.ExpiresAt)) {
+			apiKeyAuthError(ctx, APIKeyInvalidErrMsg)
+			return
+		}
+		SetPrincipal(ctx, &Principal{Username: apiKey.Client, AuthMethod: APIKeyAuthMethod})
+		ctx.Next()
+	}
+}
+
+// apiKeyAuthError writes an unauthorized error to the gin context if the API key authentication fails.
+func apiKeyAuthError(ctx *gin.Context, msg string) {
+	err := errors.UnauthorizedError(msg)
+	abortWithError(ctx, err, RestErrMsg{Error: err.Message})
+	logger.Info(err.Message)
+}
@@ -0,0 +1,144 @@
+package httputils
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, hash, err := GenerateAPIKey()
+	assert.NoError(t, err)
+	assert.Equal(t, HashAPIKey(key), hash)
+	assert.Len(t, hash, 64)
+
+	other, _, _ := GenerateAPIKey()
+	assert.NotEqual(t, key, other)
+}
+
+func TestMemoryAPIKeyStore(t *testing.T) {
+	_, err := NewMemoryAPIKeyStore(APIKey{Client: "billing", Hash: "plaintext"})
+	assert.EqualError(t, err, invalidAPIKeyErrMsg)
+	_, err = NewMemoryAPIKeyStore(APIKey{Hash: HashAPIKey("key")})
+	assert.EqualError(t, err, invalidAPIKeyErrMsg)
+
+	store, err := NewMemoryAPIKeyStore(APIKey{Client: "billing", Hash: HashAPIKey("key")})
+	assert.NoError(t, err)
+	key, err := store.Lookup(HashAPIKey("key"))
+	assert.NoError(t, err)
+	assert.Equal(t, "billing", key.Client)
+
+	store.Remove(HashAPIKey("key"))
+	key, err = store.Lookup(HashAPIKey("key"))
+	assert.NoError(t, err)
+	assert.Nil(t, key)
+}
+
+func TestLoadAPIKeyStore(t *testing.T) {
+	keys := []APIKey{
+		{Client: "billing", Hash: HashAPIKey("old"), ExpiresAt: time.Now().Add(time.Hour)},
+		{Client: "billing", Hash: HashAPIKey("new")},
+	}
+	data, _ := json.Marshal(keys)
+	filePath := filepath.Join(t.TempDir(), "apikeys.json")
+	assert.NoError(t, os.WriteFile(filePath, data, 0600))
+
+	store, err := LoadAPIKeyStore(filePath)
+	assert.NoError(t, err)
+	for _, k := range []string{"old", "new"} {
+		key, err := store.Lookup(HashAPIKey(k))
+		assert.NoError(t, err)
+		assert.Equal(t, "billing", key.Client)
+	}
+
+	_, err = LoadAPIKeyStore(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	store, err := NewMemoryAPIKeyStore(
+		APIKey{Client: "billing", Hash: HashAPIKey("old"), ExpiresAt: time.Now().Add(time.Hour)},
+		APIKey{Client: "billing", Hash: HashAPIKey("new")},
+		APIKey{Client: "reports", Hash: HashAPIKey("expired"), ExpiresAt: time.Now().Add(-time.Hour)},
+	)
+	assert.NoError(t, err)
+	router := setupMockRouter(APIKeyAuth(APIKeyConfig{QueryParam: "api_key", Store: store}))
+	router.GET("/client", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, ctx.GetString(AuthUserKey))
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		key    string
+		status int
+		body   string
+	}{
+		{"header", "/client", "old", http.StatusOK, "billing"},
+		{"rotated key", "/client", "new", http.StatusOK, "billing"},
+		{"query", "/client?api_key=new", "", http.StatusOK, "billing"},
+		{"expired", "/client", "expired", http.StatusUnauthorized, `{"error":"` + APIKeyInvalidErrMsg + `"}`},
+		{"unknown", "/client", "unknown", http.StatusUnauthorized, `{"error":"` + APIKeyInvalidErrMsg + `"}`},
+		{"missing", "/client", "", http.StatusUnauthorized, `{"error":"` + APIKeyRequiredErrMsg + `"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.key != "" {
+				req.Header.Set(APIKeyHeaderKey, tt.key)
+			}
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.body, w.Body.String())
+		})
+	}
+}
+
+func TestAPIKeyAuth_NilStore(t *testing.T) {
+	assert.PanicsWithValue(t, nilAPIKeyStoreErrMsg, func() {
+		APIKeyAuth(APIKeyConfig{})
+	})
+}
+
+func TestAPIKeyAuth_QueryParamNotLogged(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	current := logger.L()
+	assert.NoError(t, logger.ReplaceGlobal(logger.NewFromZap(zap.New(core))))
+	defer logger.ReplaceGlobal(current)
+
+	store, _ := NewMemoryAPIKeyStore(APIKey{Client: "billing", Hash: HashAPIKey("secret-key")})
+	router := setupMockRouter(logger.GinZap())
+	router.Use(APIKeyAuth(APIKeyConfig{QueryParam: "api_key", Store: store}))
+	router.GET("/client", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	for _, key := range []string{"secret-key", "unknown-key"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/client?api_key="+key, nil)
+		router.ServeHTTP(w, req)
+	}
+	requests := logs.FilterMessage("/client").All()
+	if assert.Len(t, requests, 2) {
+		for _, entry := range requests {
+			assert.Equal(t, "api_key=REDACTED", entry.ContextMap()["query"])
+		}
+	}
+	for _, entry := range logs.All() {
+		assert.NotContains(t, entry.Message, "-key")
+		for _, v := range entry.ContextMap() {
+			assert.NotContains(t, fmt.Sprint(v), "-key")
+		}
+	}
+}
@@ -5,17 +5,25 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/privatesquare/bkst-go-utils/utils/errors"
 	"github.com/privatesquare/bkst-go-utils/utils/logger"
+	"github.com/privatesquare/bkst-go-utils/utils/secrets"
 	"strings"
 )
 
 const (
 	AuthUserKey              = "username"
-	AuthPassKey              = "password"
+	BasicAuthScheme          = "Basic"
 	BasicAuthRequiredErrMsg  = "401 unauthorized: Basic authentication is required"
 	BasicAuthFailedErrMsg    = "401 unauthorized: username or password is incorrect"
 	authenticationSuccessMsg = "Authenticated successfully"
+	accountStoreErrMsg       = "Unable to authenticate the user account"
 )
 
+// AuthPassKey was the gin context key of the basic authentication password.
+//
+// Deprecated: the password is no longer written to the gin context, use GetPrincipal to read the authenticated
+// user.
+const AuthPassKey = "password"
+
 // BasicAuthRequiredError represents a error when basic authentication is not provided while making
 // a http request to the server.
 type BasicAuthRequiredError struct{}
@@ -26,7 +34,7 @@ func (e BasicAuthRequiredError) Error() string {
 }
 
 // BasicAuthRequired is a gin middleware for checking if basic authentication is provided in the request
-// The method writes the basic auth to the gin context
+// The method writes the basic auth username to the gin context
 // The method returns an error if basic authentication is not set
 func BasicAuthRequired() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -40,72 +48,92 @@ func BasicAuthRequired() gin.HandlerFunc {
 
 // BasicAuth is a gin middleware for validation if basic authentication is provided in the request
 // and the auth user and password matches with the stored user accounts.
-// The method writes the basic auth to the gin context
+// The passwords of the accounts are plaintext, use BasicAuthWithStore with a secrets.HashedAccountStore to
+// keep only password hashes in memory and configuration.
+// The method writes the authenticated Principal to the gin context
 // The method returns an error if basic authentication is not set and
 // if the authentication fails to match with a user account.
 func BasicAuth(accounts map[string]string) gin.HandlerFunc {
+	return BasicAuthWithStore(secrets.PlainAccountStore(accounts))
+}
+
+// BasicAuthWithStore is a gin middleware for validation if basic authentication is provided in the request
+// and the auth user and password are authenticated by the account store.
+// The method writes the authenticated Principal to the gin context, the password is discarded
+// The method returns an error if basic authentication is not set and
+// if the authentication fails or the account store returns an error.
+func BasicAuthWithStore(store secrets.AccountStore) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		if err := GetBasicAuthFromHeader(ctx); err != nil {
+		username, password, err := getBasicAuthCredentials(ctx)
+		if err != nil {
 			BasicAuthError(ctx)
 			return
 		}
 
-		if val, ok := accounts[ctx.GetString(AuthUserKey)]; ok {
-			if val == ctx.GetString(AuthPassKey) {
-				logger.Info(authenticationSuccessMsg)
-			} else {
-				BasicAuthFailed(ctx)
-				return
-			}
-		} else {
+		ok, err := store.Authenticate(username, password)
+		if err != nil {
+			logger.Error(accountStoreErrMsg, err)
+		}
+		if !ok {
 			BasicAuthFailed(ctx)
 			return
 		}
+		SetPrincipal(ctx, &Principal{Username: username, AuthMethod: BasicAuthMethod})
+		logger.Info(authenticationSuccessMsg)
 	}
 }
 
 // GetBasicAuthFromHeader gets basics authentication from the Authorization header.
+// The method writes the username to the gin context, the password is not stored.
 func GetBasicAuthFromHeader(ctx *gin.Context) error {
+	username, _, err := getBasicAuthCredentials(ctx)
+	if err != nil {
+		return err
+	}
+	ctx.Set(AuthUserKey, username)
+	return nil
+}
+
+// getBasicAuthCredentials returns the username and the password of the Authorization header.
+func getBasicAuthCredentials(ctx *gin.Context) (string, string, error) {
 
 	if ctx.Request.Header.Get(AuthorizationHeaderKey) == "" {
-		return BasicAuthRequiredError{}
+		return "", "", BasicAuthRequiredError{}
 	}
 
 	auth := strings.SplitN(ctx.Request.Header.Get(AuthorizationHeaderKey), " ", 2)
 
-	if len(auth) != 2 || auth[0] != "Basic" {
-		return BasicAuthRequiredError{}
+	if len(auth) != 2 || auth[0] != BasicAuthScheme {
+		return "", "", BasicAuthRequiredError{}
 	}
 
 	dAuth, err := base64.StdEncoding.DecodeString(auth[1])
 	if err != nil {
-		return BasicAuthRequiredError{}
+		return "", "", BasicAuthRequiredError{}
 	}
 
 	cred := strings.SplitN(string(dAuth), ":", 2)
 
 	if len(cred) != 2 {
-		return BasicAuthRequiredError{}
+		return "", "", BasicAuthRequiredError{}
 	}
 
-	ctx.Set(AuthUserKey, cred[0])
-	ctx.Set(AuthPassKey, cred[1])
-
-	return nil
+	return cred[0], cred[1], nil
 }
 
-// BasicAuthError writes an error to the gin context if basic authentication is not provided
+// BasicAuthError writes an error and the WWW-Authenticate header to the gin context if basic authentication
+// is not provided
 func BasicAuthError(ctx *gin.Context) {
 	err := errors.UnauthorizedError(BasicAuthRequiredErrMsg)
-	ctx.JSON(err.StatusCode, RestErrMsg{Error: err.Message})
+	setWWWAuthenticate(ctx, BasicAuthScheme, `charset="UTF-8"`)
+	abortWithError(ctx, err, RestErrMsg{Error: err.Message})
 	logger.Info(err.Message)
-	ctx.Abort()
 }
 
-// BasicAuthFailed writes a error to the gin context if basic authentication fails
+// BasicAuthFailed writes a error and the WWW-Authenticate header to the gin context if basic authentication fails
 func BasicAuthFailed(ctx *gin.Context) {
 	err := errors.UnauthorizedError(BasicAuthFailedErrMsg)
-	ctx.JSON(err.StatusCode, RestErrMsg{Error: err.Message})
+	setWWWAuthenticate(ctx, BasicAuthScheme, `charset="UTF-8"`)
+	abortWithError(ctx, err, RestErrMsg{Error: err.Message})
 	logger.Info(err.Message)
-	ctx.Abort()
 }
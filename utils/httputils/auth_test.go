@@ -2,6 +2,7 @@ package httputils
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/secrets"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
@@ -146,3 +147,27 @@ func TestBasicAuth_Success_InvalidAuth(t *testing.T) {
 	assert.Equal(t, 401, w.Code)
 	assert.Equal(t, invalidAuthResponse, w.Body.String())
 }
+
+func TestBasicAuthWithStore(t *testing.T) {
+	hash, err := secrets.HashPassword("admin", secrets.BcryptHashAlgorithm)
+	assert.NoError(t, err)
+	store, err := secrets.NewHashedAccountStore(map[string]string{"admin": hash})
+	assert.NoError(t, err)
+	router := setupMockRouter(BasicAuthWithStore(store))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/login", nil)
+	req.SetBasicAuth("admin", "admin")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "OK", w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/login", nil)
+	req.SetBasicAuth("admin", hash)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 401, w.Code)
+	assert.Equal(t, invalidAuthResponse, w.Body.String())
+}
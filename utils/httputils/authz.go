@@ -0,0 +1,147 @@
+package httputils
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/privatesquare/bkst-go-utils/utils/fileutils"
+	"github.com/privatesquare/bkst-go-utils/utils/logger"
+	"github.com/privatesquare/bkst-go-utils/utils/slice"
+	"strings"
+)
+
+const (
+	AuthRolesKey        = "roles"
+	RolesClaim          = "roles"
+	ScopeClaim          = "scope"
+	ScopesClaim         = "scp"
+	authorizationLogMsg = "Authorization failed for the user '%s' on %s %s"
+	roleProviderErrMsg  = "Unable to get the roles of the user '%s'"
+	noRolesErrMsg       = "RequireRoles requires at least one role"
+	noScopesErrMsg      = "RequireScopes requires at least one scope"
+)
+
+// RoleProvider provides the roles assigned to a user.
+type RoleProvider interface {
+	// Roles returns the roles of the user that was authenticated with the auth method, for example
+	// BasicAuthMethod, so that the users of different auth methods with the same name don't share roles.
+	// The method returns no roles if the user is unknown.
+	Roles(authMethod, username string) ([]string, error)
+}
+
+// StaticRoleProvider is a RoleProvider of the roles by auth method and username.
+type StaticRoleProvider map[string]map[string][]string
+
+// Roles returns the roles of the user of the auth method.
+func (p StaticRoleProvider) Roles(authMethod, username string) ([]string, error) {
+	return p[authMethod][username], nil
+}
+
+// LoadRoleProvider reads a json file with the roles by auth method and username into a StaticRoleProvider,
+// for example {"basic": {"admin": ["admin", "user"], "john": ["user"]}, "apikey": {"billing": ["user"]}}.
+func LoadRoleProvider(filePath string) (StaticRoleProvider, error) {
+	p := make(StaticRoleProvider)
+	if err := fileutils.ReadJsonFile(filePath, &p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// AssignRoles is a gin middleware that writes the roles of the authenticated Principal from the role provider
+// to the gin context and adds them to the roles of the Principal. The middleware must follow an authentication
+// middleware that sets the Principal, the requests without a Principal get no roles.
+func AssignRoles(provider RoleProvider) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		p, ok := GetPrincipal(ctx)
+		if !ok || p.Username == "" {
+			ctx.Next()
+			return
+		}
+		roles, err := provider.Roles(p.AuthMethod, p.Username)
+		if err != nil {
+			logger.Error(fmt.Sprintf(roleProviderErrMsg, p.Username), err)
+			AbortWithRestErr(ctx, errors.InternalServerError(InternalServerErrMsg))
+			return
+		}
+		ctx.Set(AuthRolesKey, roles)
+		p.Roles = appendMissing(p.Roles, roles...)
+		ctx.Next()
+	}
+}
+
+// GetRoles returns the roles of the request, which are the roles of the Principal and the roles assigned by
+// AssignRoles.
+func GetRoles(ctx *gin.Context) []string {
+	var roles []string
+	if p, ok := GetPrincipal(ctx); ok {
+		roles = appendMissing(roles, p.Roles...)
+	}
+	return appendMissing(roles, ctx.GetStringSlice(AuthRolesKey)...)
+}
+
+// GetScopes returns the scopes of the request from the space separated "scope" claim or the "scp" list claim
+// of a JWT.
+func GetScopes(ctx *gin.Context) []string {
+	claims, ok := GetJWTClaims(ctx)
+	if !ok {
+		return nil
+	}
+	if scope := claims.String(ScopeClaim); scope != "" {
+		return strings.Fields(scope)
+	}
+	return claims.Strings(ScopesClaim)
+}
+
+// RequireRoles is a gin middleware that checks if the request has at least one of the roles.
+// The method writes a forbidden error to the gin context if the request has none of the roles.
+// The method panics if no role is given, so that a misconfigured route is noticed when the router is built.
+func RequireRoles(roles ...string) gin.HandlerFunc {
+	if len(roles) == 0 {
+		panic(noRolesErrMsg)
+	}
+	return func(ctx *gin.Context) {
+		granted := GetRoles(ctx)
+		for _, role := range roles {
+			if slice.EntryExists(granted, role) {
+				ctx.Next()
+				return
+			}
+		}
+		AuthorizationFailed(ctx)
+	}
+}
+
+// RequireScopes is a gin middleware that checks if the request has all the scopes.
+// The method writes a forbidden error to the gin context if a scope is missing.
+// The method panics if no scope is given, so that a misconfigured route is noticed when the router is built.
+func RequireScopes(scopes ...string) gin.HandlerFunc {
+	if len(scopes) == 0 {
+		panic(noScopesErrMsg)
+	}
+	return func(ctx *gin.Context) {
+		granted := GetScopes(ctx)
+		for _, scope := range scopes {
+			if !slice.EntryExists(granted, scope) {
+				AuthorizationFailed(ctx)
+				return
+			}
+		}
+		ctx.Next()
+	}
+}
+
+// AuthorizationFailed writes a forbidden error to the gin context if the user has insufficient privileges.
+func AuthorizationFailed(ctx *gin.Context) {
+	AbortWithRestErr(ctx, errors.ForbiddenError(AuthorizationErrMsg))
+	logger.Infof(authorizationLogMsg, ctx.GetString(AuthUserKey), ctx.Request.Method, ctx.Request.URL.Path)
+}
+
+// appendMissing appends the values that are not in the slice yet.
+func appendMissing(s []string, values ...string) []string {
+	for _, v := range values {
+		if !slice.EntryExists(s, v) {
+			s = append(s, v)
+		}
+	}
+	return s
+}
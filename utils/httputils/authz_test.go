@@ -0,0 +1,148 @@
+package httputils
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var (
+	forbiddenResponse = `{"message":"Insufficient privileges","status":403,"error":"Forbidden"}`
+)
+
+type errRoleProvider struct{}
+
+func (p errRoleProvider) Roles(string, string) ([]string, error) {
+	return nil, errors.New("provider unavailable")
+}
+
+func TestLoadRoleProvider(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "roles.json")
+	data := `{"basic":{"admin":["admin","user"],"user":["user"]},"apikey":{"billing":["user"]}}`
+	assert.NoError(t, os.WriteFile(filePath, []byte(data), 0600))
+
+	p, err := LoadRoleProvider(filePath)
+	assert.NoError(t, err)
+	roles, err := p.Roles(BasicAuthMethod, "admin")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"admin", "user"}, roles)
+	roles, _ = p.Roles(BasicAuthMethod, "unknown")
+	assert.Empty(t, roles)
+	roles, _ = p.Roles(APIKeyAuthMethod, "admin")
+	assert.Empty(t, roles)
+
+	_, err = LoadRoleProvider(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestRequireRoles(t *testing.T) {
+	router := setupMockRouter(BasicAuth(getAccount()))
+	admin := router.Group("/admin", AssignRoles(StaticRoleProvider{
+		BasicAuthMethod: {"admin": {"admin", "user"}, "user": {"user"}},
+	}))
+	admin.GET("", RequireRoles("admin", "operator"), func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "OK")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/admin", nil)
+	req.SetBasicAuth("admin", "admin")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/admin", nil)
+	req.SetBasicAuth("user", "user")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, forbiddenResponse, w.Body.String())
+}
+
+func TestAssignRoles_Unauthenticated(t *testing.T) {
+	provider := StaticRoleProvider{BasicAuthMethod: {"admin": {"admin"}}}
+	handler := func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "OK")
+	}
+
+	// the username of a Basic header that was not verified gets no roles
+	router := setupMockRouter(BasicAuthRequired())
+	router.GET("/admin", AssignRoles(provider), RequireRoles("admin"), handler)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/admin", nil)
+	req.SetBasicAuth("admin", "anything-at-all")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// an API key client with the name of a basic auth user gets no roles of the user
+	store, _ := NewMemoryAPIKeyStore(APIKey{Client: "admin", Hash: HashAPIKey("key")})
+	router = setupMockRouter(APIKeyAuth(APIKeyConfig{Store: store}))
+	router.GET("/admin", AssignRoles(provider), RequireRoles("admin"), handler)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set(APIKeyHeaderKey, "key")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAssignRoles_ProviderError(t *testing.T) {
+	router := setupMockRouter(BasicAuth(getAccount()))
+	router.GET("/admin", AssignRoles(errRoleProvider{}), RequireRoles("admin"), func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "OK")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/admin", nil)
+	req.SetBasicAuth("admin", "admin")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestRequireScopes(t *testing.T) {
+	router := setupMockRouter(BearerAuth(JWTConfig{Keys: JWTKeySet{"": jwtSecret}}))
+	router.GET("/users", RequireScopes("users:read"), RequireRoles("user"), func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{"roles": GetRoles(ctx), "scopes": GetScopes(ctx)})
+	})
+	router.GET("/users/write", RequireScopes("users:read", "users:write"), func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "OK")
+	})
+
+	claims := validClaims()
+	claims["scope"] = "users:read openid"
+	claims["roles"] = []string{"user"}
+	token := signJWT(t, HS256, "", jwtSecret, claims)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set(AuthorizationHeaderKey, "Bearer "+token)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"roles":["user"],"scopes":["users:read","openid"]}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/users/write", nil)
+	req.Header.Set(AuthorizationHeaderKey, "Bearer "+token)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	delete(claims, "scope")
+	claims["scp"] = []string{"users:read", "users:write"}
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/users/write", nil)
+	req.Header.Set(AuthorizationHeaderKey, "Bearer "+signJWT(t, HS256, "", jwtSecret, claims))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRequireRolesAndScopes_Empty(t *testing.T) {
+	assert.PanicsWithValue(t, noRolesErrMsg, func() { RequireRoles() })
+	assert.PanicsWithValue(t, noScopesErrMsg, func() { RequireScopes() })
+	assert.NotPanics(t, func() {
+		RequireRoles("admin")
+		RequireScopes("users:read")
+	})
+}
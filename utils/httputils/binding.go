@@ -0,0 +1,161 @@
+package httputils
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/privatesquare/bkst-go-utils/utils/structutils"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	formStructFieldKey = "form"
+	uriStructFieldKey  = "uri"
+)
+
+var (
+	// validationRuleMsgs are the messages of the common validation rules. The "%s" verb is replaced by the parameter
+	// of the rule.
+	validationRuleMsgs = map[string]string{
+		"required": "is required",
+		"email":    "must be a valid email address",
+		"url":      "must be a valid url",
+		"uri":      "must be a valid uri",
+		"uuid":     "must be a valid uuid",
+		"numeric":  "must be a numeric value",
+		"alpha":    "must contain only letters",
+		"alphanum": "must contain only letters and numbers",
+		"len":      "must have a length of %s",
+		"min":      "must be at least %s",
+		"max":      "must be at most %s",
+		"gt":       "must be greater than %s",
+		"gte":      "must be greater than or equal to %s",
+		"lt":       "must be less than %s",
+		"lte":      "must be less than or equal to %s",
+		"eq":       "must be equal to %s",
+		"ne":       "must not be equal to %s",
+		"oneof":    "must be one of [%s]",
+	}
+	defaultValidationRuleMsg = "failed on the '%s' validation"
+)
+
+// BindJSON binds the json request body to obj and validates it with gin's validator.
+// The method returns a bad request RestErr with the list of the fields that failed the validation.
+// The field names are taken from the json tags of obj.
+func BindJSON(ctx *gin.Context, obj interface{}) *errors.RestErr {
+	return bindingError(obj, ctx.ShouldBindJSON(obj), structutils.JsonStructFieldKey)
+}
+
+// BindQuery binds the request query parameters to obj and validates it with gin's validator.
+// The method returns a bad request RestErr with the list of the fields that failed the validation.
+// The field names are taken from the form tags of obj and fall back to the json tags.
+func BindQuery(ctx *gin.Context, obj interface{}) *errors.RestErr {
+	return bindingError(obj, ctx.ShouldBindQuery(obj), formStructFieldKey)
+}
+
+// BindURI binds the request path parameters to obj and validates it with gin's validator.
+// The method returns a bad request RestErr with the list of the fields that failed the validation.
+// The field names are taken from the uri tags of obj and fall back to the json tags.
+func BindURI(ctx *gin.Context, obj interface{}) *errors.RestErr {
+	return bindingError(obj, ctx.ShouldBindUri(obj), uriStructFieldKey)
+}
+
+// bindingError converts a binding error into a bad request RestErr.
+func bindingError(obj interface{}, err error, tagKey string) *errors.RestErr {
+	if err == nil {
+		return nil
+	}
+	validationErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return errors.BadRequestError(InvalidPayloadErrMsg).WithCause(err)
+	}
+	errs := errors.NewMultiError()
+	for _, fe := range validationErrs {
+		field := fieldName(obj, fe, tagKey)
+		errs.Append(errors.FieldError{
+			Field:   field,
+			Rule:    fe.Tag(),
+			Message: field + " " + validationRuleMsg(fe),
+		})
+	}
+	return errs.RestErr(http.StatusBadRequest, InvalidPayloadErrMsg)
+}
+
+// validationRuleMsg returns a readable message for a failed validation rule.
+func validationRuleMsg(fe validator.FieldError) string {
+	if msg, ok := validationRuleMsgs[fe.Tag()]; ok {
+		if strings.Contains(msg, "%s") {
+			return fmt.Sprintf(msg, fe.Param())
+		}
+		return msg
+	}
+	return fmt.Sprintf(defaultValidationRuleMsg, fe.Tag())
+}
+
+// fieldName returns the name of the field that failed the validation using the tags of the struct fields.
+// Nested fields are joined with a ".", for example "address.street" or "items[0].name".
+// The method falls back to the name reported by the validator if the field cannot be resolved.
+func fieldName(obj interface{}, fe validator.FieldError, tagKey string) string {
+	v := reflect.ValueOf(obj)
+	parts := strings.Split(fe.StructNamespace(), ".")
+	names := make([]string, 0, len(parts))
+
+	for _, part := range parts[1:] {
+		name, index := part, ""
+		if i := strings.Index(part, "["); i >= 0 {
+			name, index = part[:i], part[i:]
+		}
+		for v.Kind() == reflect.Ptr && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return fe.Field()
+		}
+		sf, ok := v.Type().FieldByName(name)
+		if !ok {
+			return fe.Field()
+		}
+		names = append(names, fieldTagName(sf, tagKey)+index)
+		f := v
+		// the fields of embedded structs are resolved through nil pointers without a panic
+		for _, i := range sf.Index {
+			for f.Kind() == reflect.Ptr && !f.IsNil() {
+				f = f.Elem()
+			}
+			if f.Kind() != reflect.Struct {
+				return fe.Field()
+			}
+			f = f.Field(i)
+		}
+
+		v = f
+		if index != "" {
+			i, err := strconv.Atoi(strings.Trim(index, "[]"))
+			for v.Kind() == reflect.Ptr && !v.IsNil() {
+				v = v.Elem()
+			}
+			if err != nil || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || i >= v.Len() {
+				return fe.Field()
+			}
+			v = v.Index(i)
+		}
+	}
+	return strings.Join(names, ".")
+}
+
+// fieldTagName returns the tag value of the struct field for the tag key, falling back to the json tag and the
+// struct field name. The tag is read from the type, so that structs with unexported fields are supported.
+func fieldTagName(sf reflect.StructField, tagKey string) string {
+	tag := strings.Split(sf.Tag.Get(tagKey), ",")[0]
+	if tag == "" || tag == "-" {
+		tag = strings.Split(sf.Tag.Get(structutils.JsonStructFieldKey), ",")[0]
+	}
+	if tag == "" || tag == "-" {
+		tag = sf.Name
+	}
+	return tag
+}
@@ -0,0 +1,128 @@
+package httputils
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type mockAddress struct {
+	Street string `json:"street" binding:"required"`
+}
+
+type mockItem struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type mockPayload struct {
+	Name    string      `json:"name" binding:"required"`
+	Email   string      `json:"email_address" binding:"omitempty,email"`
+	Age     int         `json:"age" binding:"gte=18"`
+	Address mockAddress `json:"address"`
+	Items   []mockItem  `json:"items" binding:"dive"`
+}
+
+type mockUnexportedPayload struct {
+	Name   string `json:"name" binding:"required"`
+	secret string
+}
+
+type mockQuery struct {
+	Page int `form:"page" binding:"min=1"`
+}
+
+type mockUri struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+func newBindingContext(method, path, body string) *gin.Context {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request, _ = http.NewRequest(method, path, strings.NewReader(body))
+	ctx.Request.Header.Set(ContentTypeHeaderKey, ApplicationJsonMIMEType)
+	return ctx
+}
+
+func TestBindJSON(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		ctx := newBindingContext(http.MethodPost, "/", `{"name":"foo","age":20,"address":{"street":"bar"}}`)
+		payload := new(mockPayload)
+		assert.Nil(t, BindJSON(ctx, payload))
+		assert.Equal(t, "foo", payload.Name)
+	})
+
+	t.Run("validation errors", func(t *testing.T) {
+		ctx := newBindingContext(http.MethodPost, "/", `{"email_address":"foo","age":10,"items":[{"name":"a"},{}]}`)
+		err := BindJSON(ctx, new(mockPayload))
+		if assert.NotNil(t, err) {
+			assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+			assert.Equal(t, InvalidPayloadErrMsg, err.Message)
+			assert.Equal(t, []errors.FieldError{
+				{Field: "name", Rule: "required", Message: "name is required"},
+				{Field: "email_address", Rule: "email", Message: "email_address must be a valid email address"},
+				{Field: "age", Rule: "gte", Message: "age must be greater than or equal to 18"},
+				{Field: "address.street", Rule: "required", Message: "address.street is required"},
+				{Field: "items[1].name", Rule: "required", Message: "items[1].name is required"},
+			}, err.Fields)
+		}
+	})
+
+	t.Run("unexported field", func(t *testing.T) {
+		ctx := newBindingContext(http.MethodPost, "/", `{}`)
+		payload := &mockUnexportedPayload{secret: "secret"}
+		err := BindJSON(ctx, payload)
+		if assert.NotNil(t, err) {
+			assert.Equal(t, []errors.FieldError{
+				{Field: "name", Rule: "required", Message: "name is required"},
+			}, err.Fields)
+		}
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		ctx := newBindingContext(http.MethodPost, "/", `{"name":`)
+		err := BindJSON(ctx, new(mockPayload))
+		if assert.NotNil(t, err) {
+			assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+			assert.Equal(t, InvalidPayloadErrMsg, err.Message)
+			assert.Equal(t, http.StatusText(http.StatusBadRequest), err.Err)
+			assert.NotNil(t, err.Cause())
+			assert.Empty(t, err.Fields)
+		}
+	})
+}
+
+func TestBindQuery(t *testing.T) {
+	ctx := newBindingContext(http.MethodGet, "/?page=0", "")
+	err := BindQuery(ctx, new(mockQuery))
+	if assert.NotNil(t, err) {
+		assert.Equal(t, []errors.FieldError{
+			{Field: "page", Rule: "min", Message: "page must be at least 1"},
+		}, err.Fields)
+	}
+
+	ctx = newBindingContext(http.MethodGet, "/?page=2", "")
+	query := new(mockQuery)
+	assert.Nil(t, BindQuery(ctx, query))
+	assert.Equal(t, 2, query.Page)
+}
+
+func TestBindURI(t *testing.T) {
+	r := NewRouter()
+	var err *errors.RestErr
+	r.GET("/users/:id", func(ctx *gin.Context) {
+		err = BindURI(ctx, new(mockUri))
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/users/abc", nil)
+	r.ServeHTTP(w, req)
+
+	if assert.NotNil(t, err) {
+		assert.Equal(t, []errors.FieldError{
+			{Field: "id", Rule: "uuid", Message: "id must be a valid uuid"},
+		}, err.Fields)
+	}
+}
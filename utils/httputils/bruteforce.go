@@ -0,0 +1,384 @@
+package httputils
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/privatesquare/bkst-go-utils/utils/logger"
+	"go.uber.org/zap"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultMaxLoginFailures       = 5
+	DefaultLockoutDuration        = time.Minute
+	DefaultMaxLockoutDuration     = time.Hour
+	DefaultLoginAttemptTTL        = 24 * time.Hour
+	PendingAttemptRetryAfter      = time.Second
+	LoginLockedErrMsg             = "Too many failed authentication attempts, retry in %d second(s)"
+	loginAttemptStoreErrMsg       = "Unable to check the failed authentication attempts, the request is allowed"
+	authFailureAuditMsg           = "Authentication failed"
+	authLockoutAuditMsg           = "Authentication locked after too many failures"
+	authBlockedAuditMsg           = "Authentication attempt rejected during lockout"
+	authResetAuditMsg             = "Authentication succeeded, failed attempts were reset"
+	auditEventFieldKey            = "event"
+	authFailureAuditEvent         = "authentication_failure"
+	authLockoutAuditEvent         = "authentication_lockout"
+	authBlockedAuditEvent         = "authentication_blocked"
+	authResetAuditEvent           = "authentication_reset"
+	loginAttemptUserKeyPrefix     = "user:"
+	loginAttemptClientIPKeyPrefix = "ip:"
+)
+
+// LoginAttemptStore stores the consecutive failed authentication attempts by key.
+// Implement the interface to share the failed attempts between multiple instances of a service.
+type LoginAttemptStore interface {
+	// Attempt checks if the key is locked and otherwise reserves a pending attempt in one atomic step.
+	// The pending attempts count toward the lockout until they are recorded with Fail or removed with Release,
+	// so that concurrent requests can't make more attempts than allowed. The lockout function returns the lockout
+	// duration after a number of failures. The method returns the remaining lockout duration if the key is locked.
+	Attempt(key string, lockout func(failures int) time.Duration) (time.Duration, error)
+	// Failures returns the number of consecutive failures of the key and the time of the last failure.
+	Failures(key string) (int, time.Time, error)
+	// Fail records a failure for a pending attempt of the key and returns the number of consecutive failures.
+	Fail(key string) (int, error)
+	// Succeed records a success for a pending attempt of the key and removes one failure, so that the failures
+	// of a key decay with successful attempts.
+	Succeed(key string) error
+	// Release removes a pending attempt of the key without recording a failure.
+	Release(key string) error
+	// Reset removes the failures of the key.
+	Reset(key string) error
+}
+
+// BruteForceConfig represents the configuration of the brute-force protection middleware.
+type BruteForceConfig struct {
+	// MaxFailures is the number of consecutive failures per username or client IP after which the
+	// authentication is locked. Defaults to DefaultMaxLoginFailures.
+	MaxFailures int
+	// LockoutDuration is the duration of the first lockout. The duration doubles with every further failure.
+	// Defaults to DefaultLockoutDuration.
+	LockoutDuration time.Duration
+	// MaxLockoutDuration is the maximum duration of a lockout. Defaults to DefaultMaxLockoutDuration.
+	MaxLockoutDuration time.Duration
+	// UsernameFunc returns the username of the authentication attempt. Defaults to BasicAuthUsername.
+	UsernameFunc func(ctx *gin.Context) string
+	// Store holds the failed attempts. Defaults to a new MemoryLoginAttemptStore.
+	Store LoginAttemptStore
+}
+
+// BasicAuthUsername returns the username of the basic authentication header of the request.
+func BasicAuthUsername(ctx *gin.Context) string {
+	username, _, _ := getBasicAuthCredentials(ctx)
+	return username
+}
+
+// BruteForceGuard is a gin middleware that protects the authentication middlewares that follow it against
+// brute-force and credential stuffing attacks. Every attempt is reserved per username and per client IP before
+// the authentication, and the unauthorized responses are counted as failures. After MaxFailures consecutive
+// failures the authentication is locked for an exponentially increasing duration, during which a 429 error with
+// a Retry-After header is written to the gin context.
+// A successful response resets the failures of the username and removes one failure of the client IP, so that
+// logins to a valid account can't clear the failures of a credential stuffing attack at once.
+// The failures, lockouts and resets are logged as audit events.
+func BruteForceGuard(cnf BruteForceConfig) gin.HandlerFunc {
+	if cnf.MaxFailures <= 0 {
+		cnf.MaxFailures = DefaultMaxLoginFailures
+	}
+	if cnf.LockoutDuration <= 0 {
+		cnf.LockoutDuration = DefaultLockoutDuration
+	}
+	if cnf.MaxLockoutDuration < cnf.LockoutDuration {
+		cnf.MaxLockoutDuration = DefaultMaxLockoutDuration
+	}
+	if cnf.UsernameFunc == nil {
+		cnf.UsernameFunc = BasicAuthUsername
+	}
+	if cnf.Store == nil {
+		cnf.Store = NewMemoryLoginAttemptStore(DefaultLoginAttemptTTL)
+	}
+	return func(ctx *gin.Context) {
+		user, ip := cnf.UsernameFunc(ctx), ctx.ClientIP()
+		userKey := loginAttemptUserKeyPrefix + user
+		keys := []string{loginAttemptClientIPKeyPrefix + ip}
+		if user != "" {
+			keys = append(keys, userKey)
+		}
+		fields := []zap.Field{zap.String("user", user), zap.String("ip", ip)}
+
+		var wait time.Duration
+		reserved := make([]string, 0, len(keys))
+		for _, key := range keys {
+			d, err := cnf.Store.Attempt(key, cnf.lockout)
+			if err != nil {
+				logger.Error(loginAttemptStoreErrMsg, err)
+				continue
+			}
+			if d > 0 {
+				if d > wait {
+					wait = d
+				}
+				continue
+			}
+			reserved = append(reserved, key)
+		}
+		if wait > 0 {
+			cnf.release(reserved)
+			logger.Warn(authBlockedAuditMsg, append(fields, zap.String(auditEventFieldKey, authBlockedAuditEvent))...)
+			loginLocked(ctx, wait)
+			return
+		}
+
+		completed := false
+		defer func() {
+			// the pending attempts are removed if the handlers panic
+			if !completed {
+				cnf.release(reserved)
+			}
+		}()
+		ctx.Next()
+		completed = true
+
+		switch status := ctx.Writer.Status(); {
+		case status == http.StatusUnauthorized:
+			maxFailures := 0
+			for _, key := range reserved {
+				failures, err := cnf.Store.Fail(key)
+				if err != nil {
+					logger.Error(loginAttemptStoreErrMsg, err)
+				}
+				if failures > maxFailures {
+					maxFailures = failures
+				}
+			}
+			fields = append(fields, zap.Int("failures", maxFailures))
+			logger.Warn(authFailureAuditMsg, append(fields, zap.String(auditEventFieldKey, authFailureAuditEvent))...)
+			if maxFailures >= cnf.MaxFailures {
+				fields = append(fields, zap.String("lockout", cnf.lockout(maxFailures).String()))
+				logger.Warn(authLockoutAuditMsg, append(fields, zap.String(auditEventFieldKey, authLockoutAuditEvent))...)
+			}
+		case status < http.StatusBadRequest:
+			reset := false
+			for _, key := range reserved {
+				if key != userKey {
+					if err := cnf.Store.Succeed(key); err != nil {
+						logger.Error(loginAttemptStoreErrMsg, err)
+					}
+					continue
+				}
+				if failures, _, err := cnf.Store.Failures(key); err == nil && failures > 0 {
+					reset = true
+				}
+				if err := cnf.Store.Reset(key); err != nil {
+					logger.Error(loginAttemptStoreErrMsg, err)
+				}
+			}
+			if reset {
+				logger.Info(authResetAuditMsg, append(fields, zap.String(auditEventFieldKey, authResetAuditEvent))...)
+			}
+		default:
+			cnf.release(reserved)
+		}
+	}
+}
+
+// release removes the pending attempts of the keys.
+func (cnf BruteForceConfig) release(keys []string) {
+	for _, key := range keys {
+		if err := cnf.Store.Release(key); err != nil {
+			logger.Error(loginAttemptStoreErrMsg, err)
+		}
+	}
+}
+
+// lockout returns the lockout duration after the number of consecutive failures.
+func (cnf BruteForceConfig) lockout(failures int) time.Duration {
+	if failures < cnf.MaxFailures {
+		return 0
+	}
+	d := float64(cnf.LockoutDuration) * math.Pow(2, float64(failures-cnf.MaxFailures))
+	if d > float64(cnf.MaxLockoutDuration) {
+		return cnf.MaxLockoutDuration
+	}
+	return time.Duration(d)
+}
+
+// loginLocked writes a too many requests error with a Retry-After header to the gin context.
+func loginLocked(ctx *gin.Context, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	err := errors.TooManyRequestsErrorf(LoginLockedErrMsg, seconds)
+	ctx.Header(RetryAfterHeaderKey, strconv.Itoa(seconds))
+	AbortWithRestErr(ctx, err)
+}
+
+// MemoryLoginAttemptStore is an in-memory LoginAttemptStore.
+// Failures expire the ttl duration after the last failure of the key.
+type MemoryLoginAttemptStore struct {
+	mu        sync.Mutex
+	attempts  map[string]*loginAttempts
+	ttl       time.Duration
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// loginAttempts represents the failed and the pending attempts of a single key.
+type loginAttempts struct {
+	failures int
+	pending  int
+	// last is the time of the last failure.
+	last time.Time
+	// reserved is the time of the last pending attempt.
+	reserved time.Time
+}
+
+// NewMemoryLoginAttemptStore returns a new in-memory LoginAttemptStore that expires failures after the ttl
+// duration.
+func NewMemoryLoginAttemptStore(ttl time.Duration) *MemoryLoginAttemptStore {
+	if ttl <= 0 {
+		ttl = DefaultLoginAttemptTTL
+	}
+	return &MemoryLoginAttemptStore{
+		attempts:  make(map[string]*loginAttempts),
+		ttl:       ttl,
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Attempt implements the LoginAttemptStore interface.
+// While the pending attempts reach the lockout, further attempts are retried after PendingAttemptRetryAfter.
+func (s *MemoryLoginAttemptStore) Attempt(key string, lockout func(failures int) time.Duration) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	a := s.get(key, now, true)
+	if lockout(a.failures+a.pending) > 0 {
+		if a.pending > 0 {
+			return PendingAttemptRetryAfter, nil
+		}
+		if wait := a.last.Add(lockout(a.failures)).Sub(now); wait > 0 {
+			return wait, nil
+		}
+	}
+	a.pending++
+	a.reserved = now
+	return 0, nil
+}
+
+// Failures implements the LoginAttemptStore interface.
+func (s *MemoryLoginAttemptStore) Failures(key string) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.get(key, s.now(), false)
+	if a == nil {
+		return 0, time.Time{}, nil
+	}
+	return a.failures, a.last, nil
+}
+
+// Fail implements the LoginAttemptStore interface.
+func (s *MemoryLoginAttemptStore) Fail(key string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	a := s.get(key, now, true)
+	if a.pending > 0 {
+		a.pending--
+	}
+	a.failures++
+	a.last = now
+	return a.failures, nil
+}
+
+// Succeed implements the LoginAttemptStore interface.
+func (s *MemoryLoginAttemptStore) Succeed(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.get(key, s.now(), false)
+	if a == nil {
+		return nil
+	}
+	if a.failures > 0 {
+		a.failures--
+	}
+	s.release(key, a)
+	return nil
+}
+
+// Release implements the LoginAttemptStore interface.
+func (s *MemoryLoginAttemptStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a := s.get(key, s.now(), false); a != nil {
+		s.release(key, a)
+	}
+	return nil
+}
+
+// Reset implements the LoginAttemptStore interface.
+func (s *MemoryLoginAttemptStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, key)
+	return nil
+}
+
+// Len returns the number of keys with failures or pending attempts in the store.
+func (s *MemoryLoginAttemptStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.attempts)
+}
+
+// get returns the attempts of the key with the expired failures removed, or nil if the key has no attempts.
+// The attempts of the key are created if create is true.
+func (s *MemoryLoginAttemptStore) get(key string, now time.Time, create bool) *loginAttempts {
+	s.evict(now)
+	a, ok := s.attempts[key]
+	if ok {
+		s.expire(a, now)
+	} else if create {
+		a = new(loginAttempts)
+		s.attempts[key] = a
+	}
+	return a
+}
+
+// release removes a pending attempt and removes the key if it has no failures and no pending attempts.
+func (s *MemoryLoginAttemptStore) release(key string, a *loginAttempts) {
+	if a.pending > 0 {
+		a.pending--
+	}
+	if a.failures == 0 && a.pending == 0 {
+		delete(s.attempts, key)
+	}
+}
+
+// expire removes the failures whose last failure is more than the ttl duration ago, and the pending attempts
+// that were not completed within the ttl duration.
+func (s *MemoryLoginAttemptStore) expire(a *loginAttempts, now time.Time) {
+	if a.failures > 0 && now.Sub(a.last) >= s.ttl {
+		a.failures = 0
+	}
+	if a.pending > 0 && now.Sub(a.reserved) >= s.ttl {
+		a.pending = 0
+	}
+}
+
+// evict removes the keys whose failures and pending attempts have expired.
+// The keys are checked at most once per ttl duration.
+func (s *MemoryLoginAttemptStore) evict(now time.Time) {
+	if now.Sub(s.lastSweep) < s.ttl {
+		return
+	}
+	for key, a := range s.attempts {
+		s.expire(a, now)
+		if a.failures == 0 && a.pending == 0 {
+			delete(s.attempts, key)
+		}
+	}
+	s.lastSweep = now
+}
@@ -0,0 +1,249 @@
+package httputils
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func setupBruteForceRouter(cnf BruteForceConfig) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(BruteForceGuard(cnf), BasicAuth(getAccount()))
+	r.GET("/login", func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+	return r
+}
+
+func login(router *gin.Engine, username, password, ip string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/login", nil)
+	req.SetBasicAuth(username, password)
+	req.RemoteAddr = ip + ":1234"
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestBruteForceGuard_Lockout(t *testing.T) {
+	store := NewMemoryLoginAttemptStore(time.Hour)
+	router := setupBruteForceRouter(BruteForceConfig{MaxFailures: 3, LockoutDuration: time.Minute, Store: store})
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusUnauthorized, login(router, "admin", "wrong", "10.0.0.1").Code)
+	}
+	// the user is locked, even with the correct password
+	w := login(router, "admin", "admin", "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get(RetryAfterHeaderKey))
+
+	// the user is locked from another client IP
+	assert.Equal(t, http.StatusTooManyRequests, login(router, "admin", "admin", "10.0.0.2").Code)
+
+	// the client IP is locked for other users
+	assert.Equal(t, http.StatusTooManyRequests, login(router, "user", "user", "10.0.0.1").Code)
+
+	// other users and client IPs are not affected
+	assert.Equal(t, http.StatusOK, login(router, "user", "user", "10.0.0.3").Code)
+}
+
+func TestBruteForceGuard_LockoutExpiry(t *testing.T) {
+	store := NewMemoryLoginAttemptStore(time.Hour)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	router := setupBruteForceRouter(BruteForceConfig{MaxFailures: 2, LockoutDuration: time.Minute, Store: store})
+
+	assert.Equal(t, http.StatusUnauthorized, login(router, "admin", "wrong", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusUnauthorized, login(router, "admin", "wrong", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, login(router, "admin", "admin", "10.0.0.1").Code)
+	// the failures were recorded 2 minutes ago, so the 1 minute lockout has expired
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, http.StatusOK, login(router, "admin", "admin", "10.0.0.1").Code)
+	// the success removes one failure of the client IP, the other failure is kept until it expires
+	failures, _, _ := store.Failures(loginAttemptClientIPKeyPrefix + "10.0.0.1")
+	assert.Equal(t, 1, failures)
+	assert.Equal(t, 1, store.Len())
+}
+
+func TestBruteForceGuard_ResetOnSuccess(t *testing.T) {
+	store := NewMemoryLoginAttemptStore(time.Hour)
+	router := setupBruteForceRouter(BruteForceConfig{MaxFailures: 3, Store: store})
+
+	login(router, "admin", "wrong", "10.0.0.1")
+	login(router, "admin", "wrong", "10.0.0.1")
+	assert.Equal(t, 2, store.Len())
+	assert.Equal(t, http.StatusOK, login(router, "admin", "admin", "10.0.0.1").Code)
+
+	// the failures of the user are reset and one failure of the client IP is removed
+	failures, _, _ := store.Failures(loginAttemptUserKeyPrefix + "admin")
+	assert.Equal(t, 0, failures)
+	failures, _, _ = store.Failures(loginAttemptClientIPKeyPrefix + "10.0.0.1")
+	assert.Equal(t, 1, failures)
+
+	// a successful login doesn't clear all the failures of the client IP
+	assert.Equal(t, http.StatusUnauthorized, login(router, "user", "wrong", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusUnauthorized, login(router, "other", "wrong", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, login(router, "admin", "admin", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusOK, login(router, "admin", "admin", "10.0.0.2").Code)
+}
+
+func TestBruteForceGuard_FailuresExpire(t *testing.T) {
+	store := NewMemoryLoginAttemptStore(DefaultLoginAttemptTTL)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	router := setupBruteForceRouter(BruteForceConfig{Store: store})
+
+	// one typo a day and hourly successful logins from a shared client IP don't add up to a lockout
+	for day := 0; day < 6; day++ {
+		assert.Equal(t, http.StatusUnauthorized, login(router, "admin", "wrong", "10.0.0.1").Code)
+		for hour := 0; hour < 24; hour++ {
+			now = now.Add(time.Hour)
+			assert.Equal(t, http.StatusOK, login(router, "user", "user", "10.0.0.1").Code)
+		}
+	}
+	failures, _, _ := store.Failures(loginAttemptClientIPKeyPrefix + "10.0.0.1")
+	assert.Equal(t, 0, failures)
+
+	// the failures expire after the ttl since the last failure, even if the client IP keeps sending requests
+	store = NewMemoryLoginAttemptStore(DefaultLoginAttemptTTL)
+	store.now = func() time.Time { return now }
+	router = setupBruteForceRouter(BruteForceConfig{MaxFailures: 3, Store: store})
+	for i := 0; i < 3; i++ {
+		login(router, "admin", "wrong", "10.0.0.1")
+	}
+	for hour := 0; hour <= 24; hour++ {
+		now = now.Add(time.Hour)
+		login(router, "admin", "admin", "10.0.0.1")
+	}
+	failures, _, _ = store.Failures(loginAttemptClientIPKeyPrefix + "10.0.0.1")
+	assert.Equal(t, 0, failures)
+	assert.Equal(t, http.StatusOK, login(router, "admin", "admin", "10.0.0.1").Code)
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestBruteForceGuard_Concurrent(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	store := NewMemoryLoginAttemptStore(time.Hour)
+	release := make(chan struct{})
+	router := gin.New()
+	router.Use(BruteForceGuard(BruteForceConfig{MaxFailures: 3, LockoutDuration: time.Minute, Store: store}),
+		func(ctx *gin.Context) { <-release }, BasicAuth(getAccount()))
+	router.GET("/login", func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+
+	const requests = 10
+	codes := make(chan int, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- login(router, "admin", "wrong", "10.0.0.1").Code
+		}()
+	}
+	// the requests that were not rejected wait for the authentication until the release
+	assert.Eventually(t, func() bool {
+		return len(codes) == requests-3
+	}, time.Second, 10*time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(codes)
+
+	count := make(map[int]int)
+	for code := range codes {
+		count[code]++
+	}
+	assert.Equal(t, map[int]int{http.StatusUnauthorized: 3, http.StatusTooManyRequests: requests - 3}, count)
+	failures, _, _ := store.Failures(loginAttemptUserKeyPrefix + "admin")
+	assert.Equal(t, 3, failures)
+	assert.Equal(t, http.StatusTooManyRequests, login(router, "admin", "admin", "10.0.0.2").Code)
+}
+
+func TestBruteForceConfig_Lockout(t *testing.T) {
+	cnf := BruteForceConfig{MaxFailures: 3, LockoutDuration: time.Minute, MaxLockoutDuration: 5 * time.Minute}
+	assert.Equal(t, time.Duration(0), cnf.lockout(2))
+	assert.Equal(t, time.Minute, cnf.lockout(3))
+	assert.Equal(t, 2*time.Minute, cnf.lockout(4))
+	assert.Equal(t, 4*time.Minute, cnf.lockout(5))
+	assert.Equal(t, 5*time.Minute, cnf.lockout(6))
+	assert.Equal(t, 5*time.Minute, cnf.lockout(100))
+}
+
+func TestMemoryLoginAttemptStore_Evict(t *testing.T) {
+	store := NewMemoryLoginAttemptStore(time.Minute)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	failures, err := store.Fail("key")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, failures)
+	failures, _ = store.Fail("key")
+	assert.Equal(t, 2, failures)
+
+	now = now.Add(2 * time.Minute)
+	failures, _, err = store.Failures("key")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, failures)
+	assert.Equal(t, 0, store.Len())
+
+	// the pending attempts don't keep the failures alive
+	store.Fail("key")
+	for i := 0; i < 3; i++ {
+		now = now.Add(30 * time.Second)
+		store.Attempt("key", func(int) time.Duration { return 0 })
+		store.Release("key")
+	}
+	failures, _, _ = store.Failures("key")
+	assert.Equal(t, 0, failures)
+
+	// a success removes one failure
+	store.Fail("key")
+	store.Fail("key")
+	assert.NoError(t, store.Succeed("key"))
+	failures, _, _ = store.Failures("key")
+	assert.Equal(t, 1, failures)
+	assert.NoError(t, store.Succeed("key"))
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestMemoryLoginAttemptStore_Attempt(t *testing.T) {
+	store := NewMemoryLoginAttemptStore(time.Hour)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	cnf := BruteForceConfig{MaxFailures: 2, LockoutDuration: time.Minute, MaxLockoutDuration: time.Hour}
+
+	wait, err := store.Attempt("key", cnf.lockout)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), wait)
+	wait, _ = store.Attempt("key", cnf.lockout)
+	assert.Equal(t, time.Duration(0), wait)
+
+	// the pending attempts count toward the lockout
+	wait, _ = store.Attempt("key", cnf.lockout)
+	assert.Equal(t, PendingAttemptRetryAfter, wait)
+
+	// a released attempt frees its reservation
+	assert.NoError(t, store.Release("key"))
+	wait, _ = store.Attempt("key", cnf.lockout)
+	assert.Equal(t, time.Duration(0), wait)
+
+	store.Fail("key")
+	store.Fail("key")
+	wait, _ = store.Attempt("key", cnf.lockout)
+	assert.Equal(t, time.Minute, wait)
+
+	now = now.Add(time.Minute)
+	wait, _ = store.Attempt("key", cnf.lockout)
+	assert.Equal(t, time.Duration(0), wait)
+	assert.NoError(t, store.Release("key"))
+	failures, _, _ := store.Failures("key")
+	assert.Equal(t, 2, failures)
+
+	assert.NoError(t, store.Release("other"))
+	assert.Equal(t, 1, store.Len())
+}
@@ -0,0 +1,18 @@
+package httputils
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"net/http"
+)
+
+// CodeError returns a RestErr for the error code of errors.DefaultCatalog with the message in the language
+// accepted by the request.
+func CodeError(ctx *gin.Context, code string, params errors.Params) *errors.RestErr {
+	return errors.FromCode(code, ctx.GetHeader(AcceptLanguageHeaderKey), params)
+}
+
+// ErrorCodes controller lists the error definitions of errors.DefaultCatalog.
+func ErrorCodes(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, errors.DefaultCatalog.Definitions())
+}
@@ -0,0 +1,44 @@
+package httputils
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var (
+	errorCodesResponse = `[{"code":"USER_NOT_FOUND","status":404,"message":"User '{id}' was not found","translations":{"de":"Benutzer '{id}' wurde nicht gefunden"}}]`
+)
+
+func TestCodeError(t *testing.T) {
+	errors.DefaultCatalog = errors.NewCatalog()
+	defer func() { errors.DefaultCatalog = errors.NewCatalog() }()
+	assert.NoError(t, errors.Register(errors.ErrorDefinition{
+		Code:         "USER_NOT_FOUND",
+		StatusCode:   http.StatusNotFound,
+		Message:      "User '{id}' was not found",
+		Translations: map[string]string{"de": "Benutzer '{id}' wurde nicht gefunden"},
+	}))
+
+	r := NewRouter()
+	r.GET(ErrorCodesPath, ErrorCodes)
+	r.GET("/users/:id", func(ctx *gin.Context) {
+		AbortWithRestErr(ctx, CodeError(ctx, "USER_NOT_FOUND", errors.Params{"id": ctx.Param("id")}))
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set(AcceptLanguageHeaderKey, "de-DE,de;q=0.9")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, `{"message":"Benutzer '1' wurde nicht gefunden","status":404,"error":"Not Found","code":"USER_NOT_FOUND"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, ErrorCodesPath, nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, errorCodesResponse, w.Body.String())
+}
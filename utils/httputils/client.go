@@ -0,0 +1,202 @@
+package httputils
+
+import (
+	"context"
+	"github.com/go-resty/resty/v2"
+	"github.com/privatesquare/bkst-go-utils/utils/config"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/privatesquare/bkst-go-utils/utils/logger"
+	"github.com/privatesquare/bkst-go-utils/utils/slice"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultClientTimeout          = 30 * time.Second
+	DefaultClientRetryWaitTime    = 100 * time.Millisecond
+	DefaultClientRetryMaxWaitTime = 2 * time.Second
+	clientRequestFailedMsg        = "Unable to send the request"
+)
+
+var (
+	idempotentMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete}
+)
+
+// ClientConfig represents the configuration of a REST client.
+type ClientConfig struct {
+	// BaseUrl is prepended to the paths of the requests.
+	BaseUrl string
+	// Timeout is the timeout of a single request attempt. Defaults to DefaultClientTimeout.
+	Timeout time.Duration
+	// ProxyUrl is the url of the proxy. Defaults to the proxy url of the server configuration.
+	ProxyUrl string
+	// Username and Password set basic authentication on the requests.
+	Username string
+	Password string
+	// Token sets bearer authentication on the requests.
+	Token string
+	// Headers are set on every request.
+	Headers map[string]string
+	// RetryCount is the number of times a failed idempotent request is retried. No retries are made by default.
+	RetryCount int
+	// RetryWaitTime and RetryMaxWaitTime bound the exponential backoff between the retries.
+	RetryWaitTime    time.Duration
+	RetryMaxWaitTime time.Duration
+	// Signer signs every request attempt with HMAC-SHA256, see VerifyHMAC.
+	Signer *HMACSigner
+	// BodyRedactor rewrites the request and the response bodies before they are written to the debug logs, for
+	// example to remove credentials. The bodies are logged as they are by default.
+	BodyRedactor logger.BodyRedactor
+}
+
+// Client is a REST client built on resty.
+// The client logs the requests and the responses on the debug level, retries idempotent requests that fail
+// with a transport error, a 429 or a 5xx status and maps the non 2xx responses to RestErr.
+type Client struct {
+	resty *resty.Client
+}
+
+// NewClient returns a new REST client for the configuration.
+func NewClient(cnf ClientConfig) *Client {
+	if cnf.Timeout == 0 {
+		cnf.Timeout = DefaultClientTimeout
+	}
+	if cnf.ProxyUrl == "" {
+		cnf.ProxyUrl = config.ServerCnf.ProxyUrl
+	}
+	if cnf.RetryWaitTime == 0 {
+		cnf.RetryWaitTime = DefaultClientRetryWaitTime
+	}
+	if cnf.RetryMaxWaitTime == 0 {
+		cnf.RetryMaxWaitTime = DefaultClientRetryMaxWaitTime
+	}
+
+	r := resty.New().
+		SetLogger(restyLogger{}).
+		SetHostURL(cnf.BaseUrl).
+		SetTimeout(cnf.Timeout).
+		SetHeader(AcceptHeaderKey, ApplicationJsonMIMEType).
+		SetHeaders(cnf.Headers).
+		SetRetryCount(cnf.RetryCount).
+		SetRetryWaitTime(cnf.RetryWaitTime).
+		SetRetryMaxWaitTime(cnf.RetryMaxWaitTime).
+		SetRetryAfter(retryAfter).
+		AddRetryCondition(retryCondition).
+		OnAfterResponse(func(c *resty.Client, resp *resty.Response) error {
+			if cnf.BodyRedactor != nil {
+				logger.RestyDebugLogsWithRedactor(resp, cnf.BodyRedactor)
+			} else {
+				logger.RestyDebugLogs(resp)
+			}
+			return nil
+		})
+
+	if cnf.ProxyUrl != "" {
+		r.SetProxy(cnf.ProxyUrl)
+	}
+	if cnf.Username != "" {
+		r.SetBasicAuth(cnf.Username, cnf.Password)
+	}
+	if cnf.Token != "" {
+		r.SetAuthToken(cnf.Token)
+	}
+	if cnf.Signer != nil {
+		r.SetPreRequestHook(func(c *resty.Client, req *http.Request) error {
+			return cnf.Signer.SignRequest(req)
+		})
+	}
+	return &Client{resty: r}
+}
+
+// Resty returns the underlying resty client.
+func (c *Client) Resty() *resty.Client {
+	return c.resty
+}
+
+// Get sends a GET request and decodes the json response into out if out is not nil.
+func (c *Client) Get(ctx context.Context, path string, out interface{}) *errors.RestErr {
+	return c.Do(ctx, http.MethodGet, path, nil, out)
+}
+
+// Post sends a POST request with body encoded as json and decodes the json response into out if out is not nil.
+func (c *Client) Post(ctx context.Context, path string, body, out interface{}) *errors.RestErr {
+	return c.Do(ctx, http.MethodPost, path, body, out)
+}
+
+// Put sends a PUT request with body encoded as json and decodes the json response into out if out is not nil.
+func (c *Client) Put(ctx context.Context, path string, body, out interface{}) *errors.RestErr {
+	return c.Do(ctx, http.MethodPut, path, body, out)
+}
+
+// Patch sends a PATCH request with body encoded as json and decodes the json response into out if out is not nil.
+func (c *Client) Patch(ctx context.Context, path string, body, out interface{}) *errors.RestErr {
+	return c.Do(ctx, http.MethodPatch, path, body, out)
+}
+
+// Delete sends a DELETE request and decodes the json response into out if out is not nil.
+func (c *Client) Delete(ctx context.Context, path string, out interface{}) *errors.RestErr {
+	return c.Do(ctx, http.MethodDelete, path, nil, out)
+}
+
+// Do sends a request and decodes the json response into out if out is not nil.
+// The method returns an internal server RestErr with the transport error as the cause if the request cannot be
+// sent and a RestErr with the status code of the response if the response status is not 2xx.
+func (c *Client) Do(ctx context.Context, method, path string, body, out interface{}) *errors.RestErr {
+	req := c.resty.R().SetContext(ctx)
+	if body != nil {
+		req.SetHeader(ContentTypeHeaderKey, ApplicationJsonMIMEType).SetBody(body)
+	}
+	if out != nil {
+		req.SetResult(out)
+	}
+
+	resp, err := req.Execute(method, path)
+	if err != nil {
+		logger.Error(clientRequestFailedMsg, err)
+		return errors.InternalServerError(clientRequestFailedMsg).WithCause(err)
+	}
+	if resp.IsError() {
+		return CheckHTTPErrorResponse(resp.StatusCode(), resp.Body())
+	}
+	return nil
+}
+
+// retryCondition retries idempotent requests that failed with a transport error, a 429 or a 5xx status.
+func retryCondition(resp *resty.Response, err error) bool {
+	if resp == nil || resp.Request == nil || !slice.EntryExists(idempotentMethods, resp.Request.Method) {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode() == http.StatusTooManyRequests ||
+		(resp.StatusCode() >= http.StatusInternalServerError && resp.StatusCode() != http.StatusNotImplemented)
+}
+
+// retryAfter waits for the duration of the Retry-After header if it is set in the response.
+// Zero is returned otherwise, so the exponential backoff with jitter is used.
+func retryAfter(c *resty.Client, resp *resty.Response) (time.Duration, error) {
+	if resp == nil || resp.RawResponse == nil {
+		return 0, nil
+	}
+	if seconds, err := strconv.Atoi(resp.Header().Get(RetryAfterHeaderKey)); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return 0, nil
+}
+
+// restyLogger writes the resty logs with the logger package.
+type restyLogger struct{}
+
+func (l restyLogger) Errorf(format string, v ...interface{}) {
+	logger.Errorf(format, v...)
+}
+
+func (l restyLogger) Warnf(format string, v ...interface{}) {
+	logger.Warnf(format, v...)
+}
+
+func (l restyLogger) Debugf(format string, v ...interface{}) {
+	logger.Debugf(format, v...)
+}
@@ -0,0 +1,156 @@
+package httputils
+
+import (
+	"context"
+	"github.com/jarcoal/httpmock"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/privatesquare/bkst-go-utils/utils/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	clientBaseUrl = "https://test.com"
+)
+
+type mockClientResponse struct {
+	Message string `json:"message"`
+}
+
+func newMockClient(cnf ClientConfig) *Client {
+	cnf.BaseUrl = clientBaseUrl
+	cnf.RetryWaitTime = time.Millisecond
+	cnf.RetryMaxWaitTime = time.Millisecond
+	c := NewClient(cnf)
+	httpmock.ActivateNonDefault(c.Resty().GetClient())
+	return c
+}
+
+// newCountingResponder returns a responder that responds with the status codes in order and counts the calls.
+func newCountingResponder(calls *int, statusCodes ...int) httpmock.Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		statusCode := statusCodes[len(statusCodes)-1]
+		if *calls < len(statusCodes) {
+			statusCode = statusCodes[*calls]
+		}
+		*calls++
+		return httpmock.NewStringResponse(statusCode, `{"message":"OK"}`), nil
+	}
+}
+
+func TestClient_Get(t *testing.T) {
+	c := newMockClient(ClientConfig{Token: "token"})
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(http.MethodGet, clientBaseUrl+"/items", func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "Bearer token", req.Header.Get(AuthorizationHeaderKey))
+		return NewStringToJsonResponder(http.StatusOK, `{"message":"OK"}`)(req)
+	})
+
+	out := new(mockClientResponse)
+	err := c.Get(context.Background(), "/items", out)
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", out.Message)
+}
+
+func TestClient_Post(t *testing.T) {
+	c := newMockClient(ClientConfig{Username: "admin", Password: "admin"})
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(http.MethodPost, clientBaseUrl+"/items", func(req *http.Request) (*http.Response, error) {
+		user, pass, ok := req.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "admin", user)
+		assert.Equal(t, "admin", pass)
+		assert.Equal(t, ApplicationJsonMIMEType, req.Header.Get(ContentTypeHeaderKey))
+		return NewStringToJsonResponder(http.StatusCreated, `{"message":"created"}`)(req)
+	})
+
+	out := new(mockClientResponse)
+	err := c.Post(context.Background(), "/items", map[string]string{"name": "foo"}, out)
+	assert.Nil(t, err)
+	assert.Equal(t, "created", out.Message)
+}
+
+func TestClient_BodyRedactor(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	current := logger.L()
+	assert.NoError(t, logger.ReplaceGlobal(logger.NewFromZap(zap.New(core))))
+	defer logger.ReplaceGlobal(current)
+
+	c := newMockClient(ClientConfig{BodyRedactor: func(body interface{}) interface{} {
+		if s, ok := body.(string); ok {
+			return strings.ReplaceAll(s, "secret", "***")
+		}
+		return body
+	}})
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder(http.MethodGet, clientBaseUrl+"/items",
+		NewStringToJsonResponder(http.StatusOK, `{"message":"secret"}`))
+
+	err := c.Get(context.Background(), "/items", new(mockClientResponse))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, logs.FilterMessage(`Response Body: {"message":"***"}`).Len())
+	assert.Equal(t, 0, logs.FilterMessageSnippet("secret").Len())
+}
+
+func TestClient_ErrorMapping(t *testing.T) {
+	c := newMockClient(ClientConfig{})
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(http.MethodDelete, clientBaseUrl+"/items/1", NewStringToJsonResponder(http.StatusForbidden, "denied"))
+	err := c.Delete(context.Background(), "/items/1", nil)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusForbidden, err.StatusCode)
+		assert.Equal(t, "denied", err.Message)
+	}
+
+	httpmock.RegisterResponder(http.MethodGet, clientBaseUrl+"/down", httpmock.NewErrorResponder(errors.New("connection refused")))
+	err = c.Get(context.Background(), "/down", nil)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusInternalServerError, err.StatusCode)
+		// the transport error is kept as the cause only
+		assert.Equal(t, clientRequestFailedMsg, err.Err)
+		assert.NotContains(t, err.Message, "connection refused")
+		if assert.NotNil(t, err.Cause()) {
+			assert.Contains(t, err.Cause().Error(), "connection refused")
+		}
+	}
+}
+
+func TestClient_Retry(t *testing.T) {
+	c := newMockClient(ClientConfig{RetryCount: 3})
+	defer httpmock.DeactivateAndReset()
+
+	t.Run("idempotent", func(t *testing.T) {
+		calls := 0
+		httpmock.RegisterResponder(http.MethodPut, clientBaseUrl+"/items/1",
+			newCountingResponder(&calls, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK))
+		err := c.Put(context.Background(), "/items/1", map[string]string{"name": "foo"}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("not idempotent", func(t *testing.T) {
+		calls := 0
+		httpmock.RegisterResponder(http.MethodPatch, clientBaseUrl+"/items/1",
+			newCountingResponder(&calls, http.StatusServiceUnavailable))
+		err := c.Patch(context.Background(), "/items/1", map[string]string{"name": "foo"}, nil)
+		assert.NotNil(t, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		calls := 0
+		httpmock.RegisterResponder(http.MethodGet, clientBaseUrl+"/items/1",
+			newCountingResponder(&calls, http.StatusBadRequest))
+		err := c.Get(context.Background(), "/items/1", nil)
+		assert.NotNil(t, err)
+		assert.Equal(t, 1, calls)
+	})
+}
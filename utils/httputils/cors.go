@@ -0,0 +1,154 @@
+package httputils
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/config"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/privatesquare/bkst-go-utils/utils/slice"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	OriginHeaderKey                        = "Origin"
+	VaryHeaderKey                          = "Vary"
+	AccessControlAllowOriginHeaderKey      = "Access-Control-Allow-Origin"
+	AccessControlAllowMethodsHeaderKey     = "Access-Control-Allow-Methods"
+	AccessControlAllowHeadersHeaderKey     = "Access-Control-Allow-Headers"
+	AccessControlExposeHeadersHeaderKey    = "Access-Control-Expose-Headers"
+	AccessControlAllowCredentialsHeaderKey = "Access-Control-Allow-Credentials"
+	AccessControlMaxAgeHeaderKey           = "Access-Control-Max-Age"
+	AccessControlRequestMethodHeaderKey    = "Access-Control-Request-Method"
+	AccessControlRequestHeadersHeaderKey   = "Access-Control-Request-Headers"
+	CorsOriginNotAllowedErrMsg             = "CORS origin '%s' is not allowed"
+	CorsMethodNotAllowedErrMsg             = "CORS method '%s' is not allowed"
+)
+
+var (
+	defaultCorsMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	defaultCorsHeaders = []string{OriginHeaderKey, ContentTypeHeaderKey, AcceptHeaderKey, AuthorizationHeaderKey}
+)
+
+// cors holds the normalized CORS configuration used by the CORS middleware.
+type cors struct {
+	origins          []string
+	methods          []string
+	headers          []string
+	allowAllHeaders  bool
+	exposedHeaders   string
+	allowCredentials bool
+	maxAge           string
+}
+
+// CORS is a gin middleware that handles Cross-Origin Resource Sharing for the configured origins.
+// Preflight OPTIONS requests are answered by the middleware with a 204 status, so the middleware must be added to
+// the router with Use for the preflight requests not to be handled as 404 or 405 errors.
+// Preflight requests from origins or for methods that are not allowed are rejected with a 403 error.
+// The configuration can be loaded with the config package, see config.CorsConfig.
+func CORS(cnf config.CorsConfig) gin.HandlerFunc {
+	c := newCors(cnf)
+	return func(ctx *gin.Context) {
+		origin := ctx.GetHeader(OriginHeaderKey)
+		if origin == "" {
+			ctx.Next()
+			return
+		}
+
+		ctx.Writer.Header().Add(VaryHeaderKey, OriginHeaderKey)
+		preflight := ctx.Request.Method == http.MethodOptions && ctx.GetHeader(AccessControlRequestMethodHeaderKey) != ""
+
+		if !c.isOriginAllowed(origin) {
+			if preflight {
+				err := errors.ForbiddenErrorf(CorsOriginNotAllowedErrMsg, origin)
+				AbortWithRestErr(ctx, err)
+				return
+			}
+			ctx.Next()
+			return
+		}
+
+		c.setOriginHeaders(ctx, origin)
+		if !preflight {
+			if c.exposedHeaders != "" {
+				ctx.Header(AccessControlExposeHeadersHeaderKey, c.exposedHeaders)
+			}
+			ctx.Next()
+			return
+		}
+
+		method := strings.ToUpper(ctx.GetHeader(AccessControlRequestMethodHeaderKey))
+		if !slice.EntryExists(c.methods, method) {
+			err := errors.ForbiddenErrorf(CorsMethodNotAllowedErrMsg, method)
+			AbortWithRestErr(ctx, err)
+			return
+		}
+		ctx.Header(AccessControlAllowMethodsHeaderKey, strings.Join(c.methods, ", "))
+		if c.allowAllHeaders {
+			if headers := ctx.GetHeader(AccessControlRequestHeadersHeaderKey); headers != "" {
+				ctx.Header(AccessControlAllowHeadersHeaderKey, headers)
+			}
+		} else {
+			ctx.Header(AccessControlAllowHeadersHeaderKey, strings.Join(c.headers, ", "))
+		}
+		if c.maxAge != "" {
+			ctx.Header(AccessControlMaxAgeHeaderKey, c.maxAge)
+		}
+		ctx.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// newCors normalizes the CORS configuration and sets the default methods and headers if they are not configured.
+func newCors(cnf config.CorsConfig) *cors {
+	c := &cors{
+		origins:          cnf.AllowedOrigins,
+		methods:          defaultCorsMethods,
+		headers:          defaultCorsHeaders,
+		exposedHeaders:   strings.Join(cnf.ExposedHeaders, ", "),
+		allowCredentials: cnf.AllowCredentials,
+	}
+	if len(cnf.AllowedMethods) > 0 {
+		c.methods = make([]string, len(cnf.AllowedMethods))
+		for i, m := range cnf.AllowedMethods {
+			c.methods[i] = strings.ToUpper(strings.TrimSpace(m))
+		}
+	}
+	if len(cnf.AllowedHeaders) > 0 {
+		c.headers = cnf.AllowedHeaders
+		c.allowAllHeaders = slice.EntryExists(cnf.AllowedHeaders, config.CorsWildcard)
+	}
+	if cnf.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(cnf.MaxAge)
+	}
+	return c
+}
+
+// isOriginAllowed checks if the origin matches one of the allowed origins.
+func (c *cors) isOriginAllowed(origin string) bool {
+	for _, allowed := range c.origins {
+		allowed = strings.TrimSpace(allowed)
+		if allowed == config.CorsWildcard || strings.EqualFold(allowed, origin) {
+			return true
+		}
+		if i := strings.Index(allowed, config.CorsWildcard); i >= 0 {
+			prefix, suffix := allowed[:i], allowed[i+1:]
+			if len(origin) >= len(prefix)+len(suffix) &&
+				strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// setOriginHeaders sets the allow origin and the allow credentials headers.
+func (c *cors) setOriginHeaders(ctx *gin.Context, origin string) {
+	if slice.EntryExists(c.origins, config.CorsWildcard) && !c.allowCredentials {
+		ctx.Header(AccessControlAllowOriginHeaderKey, config.CorsWildcard)
+	} else {
+		ctx.Header(AccessControlAllowOriginHeaderKey, origin)
+	}
+	if c.allowCredentials {
+		ctx.Header(AccessControlAllowCredentialsHeaderKey, "true")
+	}
+}
@@ -0,0 +1,100 @@
+package httputils
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/config"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const (
+	corsApiPath = "/cors"
+)
+
+func setupCorsRouter(cnf config.CorsConfig) *gin.Engine {
+	r := NewRouter()
+	r.Use(CORS(cnf))
+	r.GET(corsApiPath, func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "OK")
+	})
+	return r
+}
+
+func newPreflightRequest(origin, method string) *http.Request {
+	req, _ := http.NewRequest(http.MethodOptions, corsApiPath, nil)
+	req.Header.Set(OriginHeaderKey, origin)
+	req.Header.Set(AccessControlRequestMethodHeaderKey, method)
+	return req
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	r := setupCorsRouter(config.CorsConfig{
+		AllowedOrigins:   []string{"https://*.example.com"},
+		AllowedMethods:   []string{"get", "post"},
+		AllowCredentials: true,
+		MaxAge:           600,
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newPreflightRequest("https://app.example.com", http.MethodPost))
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get(AccessControlAllowOriginHeaderKey))
+	assert.Equal(t, "GET, POST", w.Header().Get(AccessControlAllowMethodsHeaderKey))
+	assert.Equal(t, "Origin, Content-Type, Accept, Authorization", w.Header().Get(AccessControlAllowHeadersHeaderKey))
+	assert.Equal(t, "true", w.Header().Get(AccessControlAllowCredentialsHeaderKey))
+	assert.Equal(t, "600", w.Header().Get(AccessControlMaxAgeHeaderKey))
+	assert.Equal(t, OriginHeaderKey, w.Header().Get(VaryHeaderKey))
+
+	// disallowed method
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, newPreflightRequest("https://app.example.com", http.MethodDelete))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// disallowed origin
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, newPreflightRequest("https://example.org", http.MethodGet))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get(AccessControlAllowOriginHeaderKey))
+}
+
+func TestCORS_SimpleRequest(t *testing.T) {
+	r := setupCorsRouter(config.CorsConfig{
+		AllowedOrigins: []string{config.CorsWildcard},
+		ExposedHeaders: []string{"X-Total-Count"},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, corsApiPath, nil)
+	req.Header.Set(OriginHeaderKey, "https://example.org")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, config.CorsWildcard, w.Header().Get(AccessControlAllowOriginHeaderKey))
+	assert.Equal(t, "X-Total-Count", w.Header().Get(AccessControlExposeHeadersHeaderKey))
+
+	// no origin
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, corsApiPath, nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(AccessControlAllowOriginHeaderKey))
+}
+
+func TestCORS_AllowAllHeaders(t *testing.T) {
+	r := setupCorsRouter(config.CorsConfig{
+		AllowedOrigins: []string{"https://example.com"},
+		AllowedHeaders: []string{config.CorsWildcard},
+	})
+
+	w := httptest.NewRecorder()
+	req := newPreflightRequest("https://example.com", http.MethodGet)
+	req.Header.Set(AccessControlRequestHeadersHeaderKey, "X-Custom")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "X-Custom", w.Header().Get(AccessControlAllowHeadersHeaderKey))
+}
@@ -0,0 +1,202 @@
+package httputils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/privatesquare/bkst-go-utils/utils/logger"
+	"github.com/privatesquare/bkst-go-utils/utils/secrets"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SignatureHeaderKey          = "X-Signature"
+	SignatureTimestampHeaderKey = "X-Signature-Timestamp"
+	SignatureKeyIdHeaderKey     = "X-Signature-Key-Id"
+	SignatureAlgorithmPrefix    = "sha256="
+	HMACAuthMethod              = "hmac"
+	DefaultSignatureMaxAge      = 5 * time.Minute
+	DefaultSignedBodyMaxBytes   = 1 << 20
+	SignatureRequiredErrMsg     = "401 unauthorized: request signature is required"
+	SignatureInvalidErrMsg      = "401 unauthorized: request signature is not valid"
+	SignatureExpiredErrMsg      = "401 unauthorized: request signature has expired"
+	SignedBodyTooLargeErrMsg    = "413 request entity too large: request body exceeds the signature size limit"
+	signatureBodyErrMsg         = "Unable to read the request body to verify the signature"
+	unknownSignatureKeyErrMsg   = "Unknown signature key id '%s'"
+)
+
+// HMACConfig represents the configuration of the HMAC signature verification middleware.
+type HMACConfig struct {
+	// Secrets are the shared secrets by key id. The secret with the empty key id is used for the requests
+	// without a key id header. Use LoadHMACSecrets to load the secrets from the environment or files.
+	Secrets map[string]string
+	// MaxAge is the replay window, the maximum difference between the signature timestamp and the current time.
+	// Defaults to DefaultSignatureMaxAge.
+	MaxAge time.Duration
+	// MaxBodyBytes is the maximum size of the request body that is read to verify the signature. The requests
+	// with a larger body are rejected before they are authenticated. Defaults to DefaultSignedBodyMaxBytes.
+	MaxBodyBytes int64
+}
+
+// HMACSigner signs requests with HMAC-SHA256.
+// The signature is computed over the method, the path with the query, the unix timestamp and the sha256 hash
+// of the body, separated by new lines, and is sent as "sha256=<hex>" in the SignatureHeaderKey header.
+type HMACSigner struct {
+	KeyId  string
+	Secret string
+}
+
+// NewHMACSigner returns a HMACSigner with the secret of the reference, see secrets.LoadSecret.
+func NewHMACSigner(keyId, secretRef string) (*HMACSigner, error) {
+	secret, err := secrets.LoadSecret(secretRef)
+	if err != nil {
+		return nil, err
+	}
+	return &HMACSigner{KeyId: keyId, Secret: secret}, nil
+}
+
+// LoadHMACSecrets returns the secrets of the references by key id, see secrets.LoadSecret.
+func LoadHMACSecrets(refs map[string]string) (map[string]string, error) {
+	loaded := make(map[string]string, len(refs))
+	for keyId, ref := range refs {
+		secret, err := secrets.LoadSecret(ref)
+		if err != nil {
+			return nil, err
+		}
+		loaded[keyId] = secret
+	}
+	return loaded, nil
+}
+
+// Signature returns the signature of the request values.
+func (s *HMACSigner) Signature(method string, u *url.URL, timestamp string, body []byte) string {
+	return SignatureAlgorithmPrefix + hex.EncodeToString(hmacSignature(s.Secret, method, u, timestamp, body))
+}
+
+// SignRequest sets the signature headers of the request. The body of the request is read and replaced.
+func (s *HMACSigner) SignRequest(req *http.Request) error {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(SignatureTimestampHeaderKey, timestamp)
+	req.Header.Set(SignatureHeaderKey, s.Signature(req.Method, req.URL, timestamp, body))
+	if s.KeyId != "" {
+		req.Header.Set(SignatureKeyIdHeaderKey, s.KeyId)
+	}
+	return nil
+}
+
+// VerifyHMAC is a gin middleware for verifying the HMAC-SHA256 signature of a request, for example a webhook.
+// The method writes a Principal with the key id to the gin context.
+// The method returns an error if the signature is missing, is not valid or is outside the replay window, and
+// a 413 error if the body is larger than MaxBodyBytes.
+func VerifyHMAC(cnf HMACConfig) gin.HandlerFunc {
+	if cnf.MaxAge <= 0 {
+		cnf.MaxAge = DefaultSignatureMaxAge
+	}
+	if cnf.MaxBodyBytes <= 0 {
+		cnf.MaxBodyBytes = DefaultSignedBodyMaxBytes
+	}
+	return func(ctx *gin.Context) {
+		signature := ctx.GetHeader(SignatureHeaderKey)
+		timestamp := ctx.GetHeader(SignatureTimestampHeaderKey)
+		if signature == "" || timestamp == "" {
+			hmacAuthError(ctx, SignatureRequiredErrMsg)
+			return
+		}
+		keyId := ctx.GetHeader(SignatureKeyIdHeaderKey)
+		secret, ok := cnf.Secrets[keyId]
+		if !ok {
+			logger.Infof(unknownSignatureKeyErrMsg, keyId)
+			hmacAuthError(ctx, SignatureInvalidErrMsg)
+			return
+		}
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			hmacAuthError(ctx, SignatureInvalidErrMsg)
+			return
+		}
+		if age := time.Since(time.Unix(unix, 0)); age > cnf.MaxAge || age < -cnf.MaxAge {
+			hmacAuthError(ctx, SignatureExpiredErrMsg)
+			return
+		}
+		if ctx.Request.ContentLength > cnf.MaxBodyBytes {
+			signedBodyTooLarge(ctx)
+			return
+		}
+		if ctx.Request.Body != nil && ctx.Request.Body != http.NoBody {
+			ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, cnf.MaxBodyBytes)
+		}
+		body, err := readRequestBody(ctx.Request)
+		if err != nil && int64(len(body)) >= cnf.MaxBodyBytes {
+			// the body of unknown length exceeded the limit while it was read
+			signedBodyTooLarge(ctx)
+			return
+		}
+		if err != nil {
+			logger.Error(signatureBodyErrMsg, err)
+			AbortWithRestErr(ctx, errors.BadRequestError(signatureBodyErrMsg))
+			return
+		}
+		expected := hmacSignature(secret, ctx.Request.Method, ctx.Request.URL, timestamp, body)
+		actual, err := hex.DecodeString(strings.TrimPrefix(signature, SignatureAlgorithmPrefix))
+		if err != nil || !strings.HasPrefix(signature, SignatureAlgorithmPrefix) || !hmac.Equal(expected, actual) {
+			hmacAuthError(ctx, SignatureInvalidErrMsg)
+			return
+		}
+		SetPrincipal(ctx, &Principal{Username: keyId, AuthMethod: HMACAuthMethod})
+		ctx.Next()
+	}
+}
+
+// hmacSignature returns the HMAC-SHA256 of the canonical request.
+func hmacSignature(secret, method string, u *url.URL, timestamp string, body []byte) []byte {
+	bodyHash := sha256.Sum256(body)
+	path := u.EscapedPath()
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join([]string{method, path, timestamp, hex.EncodeToString(bodyHash[:])}, "\n")))
+	return mac.Sum(nil)
+}
+
+// readRequestBody reads the body of the request and replaces it so that it can be read again.
+// The method returns the bytes that were read before an error with the error.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return body, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// hmacAuthError writes an unauthorized error to the gin context if the signature verification fails.
+func hmacAuthError(ctx *gin.Context, msg string) {
+	err := errors.UnauthorizedError(msg)
+	abortWithError(ctx, err, RestErrMsg{Error: err.Message})
+	logger.Info(err.Message)
+}
+
+// signedBodyTooLarge writes a request entity too large error to the gin context if the body exceeds the
+// signature size limit.
+func signedBodyTooLarge(ctx *gin.Context) {
+	err := errors.NewRestError(http.StatusRequestEntityTooLarge, SignedBodyTooLargeErrMsg)
+	abortWithError(ctx, err, RestErrMsg{Error: err.Message})
+	logger.Info(err.Message)
+}
@@ -0,0 +1,184 @@
+package httputils
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	hmacSecret = "webhook-secret"
+)
+
+func setupHMACRouter() *gin.Engine {
+	router := setupMockRouter(VerifyHMAC(HMACConfig{Secrets: map[string]string{"": hmacSecret, "github": "other"}}))
+	router.Any("/hooks", func(ctx *gin.Context) {
+		body, _ := ioutil.ReadAll(ctx.Request.Body)
+		ctx.String(http.StatusOK, ctx.GetString(AuthUserKey)+":"+string(body))
+	})
+	return router
+}
+
+func TestNewHMACSigner(t *testing.T) {
+	t.Setenv("HMAC_TEST_SECRET", hmacSecret)
+	s, err := NewHMACSigner("github", "env:HMAC_TEST_SECRET")
+	assert.NoError(t, err)
+	assert.Equal(t, &HMACSigner{KeyId: "github", Secret: hmacSecret}, s)
+
+	_, err = NewHMACSigner("github", "env:HMAC_TEST_MISSING")
+	assert.Error(t, err)
+
+	loaded, err := LoadHMACSecrets(map[string]string{"github": "env:HMAC_TEST_SECRET", "": "literal"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"github": hmacSecret, "": "literal"}, loaded)
+}
+
+func TestVerifyHMAC(t *testing.T) {
+	router := setupHMACRouter()
+	signer := &HMACSigner{Secret: hmacSecret}
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	tests := []struct {
+		name      string
+		path      string
+		body      string
+		keyId     string
+		timestamp string
+		signature func(req *http.Request) string
+		status    int
+		resp      string
+	}{
+		{
+			name: "valid", path: "/hooks?event=push", body: "payload", timestamp: now,
+			signature: func(req *http.Request) string {
+				return signer.Signature(req.Method, req.URL, now, []byte("payload"))
+			},
+			status: http.StatusOK, resp: ":payload",
+		},
+		{
+			name: "key id", path: "/hooks", body: "payload", keyId: "github", timestamp: now,
+			signature: func(req *http.Request) string {
+				return (&HMACSigner{Secret: "other"}).Signature(req.Method, req.URL, now, []byte("payload"))
+			},
+			status: http.StatusOK, resp: "github:payload",
+		},
+		{
+			name: "tampered body", path: "/hooks", body: "tampered", timestamp: now,
+			signature: func(req *http.Request) string {
+				return signer.Signature(req.Method, req.URL, now, []byte("payload"))
+			},
+			status: http.StatusUnauthorized, resp: `{"error":"` + SignatureInvalidErrMsg + `"}`,
+		},
+		{
+			name: "tampered query", path: "/hooks?event=delete", body: "payload", timestamp: now,
+			signature: func(req *http.Request) string {
+				u, _ := url.Parse("/hooks?event=push")
+				return signer.Signature(req.Method, u, now, []byte("payload"))
+			},
+			status: http.StatusUnauthorized, resp: `{"error":"` + SignatureInvalidErrMsg + `"}`,
+		},
+		{
+			name: "unknown key id", path: "/hooks", body: "payload", keyId: "unknown", timestamp: now,
+			signature: func(req *http.Request) string {
+				return signer.Signature(req.Method, req.URL, now, []byte("payload"))
+			},
+			status: http.StatusUnauthorized, resp: `{"error":"` + SignatureInvalidErrMsg + `"}`,
+		},
+		{
+			name: "replayed", path: "/hooks", body: "payload", timestamp: old,
+			signature: func(req *http.Request) string {
+				return signer.Signature(req.Method, req.URL, old, []byte("payload"))
+			},
+			status: http.StatusUnauthorized, resp: `{"error":"` + SignatureExpiredErrMsg + `"}`,
+		},
+		{
+			name: "missing", path: "/hooks", body: "payload",
+			status: http.StatusUnauthorized, resp: `{"error":"` + SignatureRequiredErrMsg + `"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			if tt.timestamp != "" {
+				req.Header.Set(SignatureTimestampHeaderKey, tt.timestamp)
+			}
+			if tt.signature != nil {
+				req.Header.Set(SignatureHeaderKey, tt.signature(req))
+			}
+			if tt.keyId != "" {
+				req.Header.Set(SignatureKeyIdHeaderKey, tt.keyId)
+			}
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.resp, w.Body.String())
+		})
+	}
+}
+
+func TestVerifyHMAC_MaxBodyBytes(t *testing.T) {
+	router := setupMockRouter(VerifyHMAC(HMACConfig{Secrets: map[string]string{"": hmacSecret}, MaxBodyBytes: 8}))
+	router.POST("/hooks", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	signer := &HMACSigner{Secret: hmacSecret}
+
+	tests := []struct {
+		name          string
+		body          string
+		contentLength int64
+		status        int
+	}{
+		{name: "within limit", body: "payload", contentLength: 7, status: http.StatusOK},
+		{name: "too large", body: "large payload", contentLength: 13, status: http.StatusRequestEntityTooLarge},
+		{name: "too large without length", body: "large payload", contentLength: -1,
+			status: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/hooks", strings.NewReader(tt.body))
+			assert.NoError(t, signer.SignRequest(req))
+			req.ContentLength = tt.contentLength
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status != http.StatusOK {
+				assert.Equal(t, `{"error":"`+SignedBodyTooLargeErrMsg+`"}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestClient_Signer(t *testing.T) {
+	c := newMockClient(ClientConfig{Signer: &HMACSigner{Secret: hmacSecret}, RetryCount: 1})
+	defer httpmock.DeactivateAndReset()
+
+	router := setupHMACRouter()
+	calls := 0
+	httpmock.RegisterResponder(http.MethodPut, clientBaseUrl+"/hooks", func(req *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return httpmock.NewStringResponse(http.StatusServiceUnavailable, ""), nil
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, `:{"event":"push"}`, w.Body.String())
+		return NewStringToJsonResponder(w.Code, `{"message":"OK"}`)(req)
+	})
+
+	out := new(mockClientResponse)
+	err := c.Put(context.Background(), "/hooks", map[string]string{"event": "push"}, out)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, "OK", out.Message)
+}
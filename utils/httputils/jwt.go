@@ -0,0 +1,383 @@
+package httputils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/privatesquare/bkst-go-utils/utils/fileutils"
+	"github.com/privatesquare/bkst-go-utils/utils/logger"
+	"github.com/privatesquare/bkst-go-utils/utils/slice"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	BearerAuthScheme         = "Bearer"
+	JWTClaimsKey             = "claims"
+	HS256                    = "HS256"
+	RS256                    = "RS256"
+	ES256                    = "ES256"
+	BearerAuthRequiredErrMsg = "401 unauthorized: Bearer token is required"
+	BearerAuthFailedErrMsg   = "401 unauthorized: Bearer token is not valid"
+
+	jwtMalformedErrMsg       = "Malformed JWT"
+	jwtAlgorithmErrMsg       = "JWT algorithm '%s' is not allowed"
+	jwtKeyNotFoundErrMsg     = "No key found for the JWT key id '%s' and algorithm '%s'"
+	jwtSignatureErrMsg       = "JWT signature is not valid"
+	jwtExpiredErrMsg         = "JWT has expired"
+	jwtExpiryRequiredErrMsg  = "JWT has no expiration time"
+	jwtTimeClaimErrMsg       = "JWT claim '%s' is not a NumericDate"
+	jwtNotValidYetErrMsg     = "JWT is not valid yet"
+	jwtIssuedInFutureErrMsg  = "JWT is issued in the future"
+	jwtIssuerErrMsg          = "JWT issuer '%s' is not valid"
+	jwtAudienceErrMsg        = "JWT audience is not valid"
+	jwtValidationErrMsg      = "Bearer token validation failed"
+	jwksKeyErrMsg            = "Invalid JWKS key '%s' : %s"
+	jwksUnsupportedKeyErrMsg = "Unsupported JWKS key type '%s' for key '%s'"
+)
+
+// JWTKeySet holds the keys used to verify JWT signatures by key id. The key with the empty key id is used for
+// tokens without a "kid" header or with a key id that is not in the set.
+// The keys are []byte secrets for HS256, *rsa.PublicKey for RS256 and *ecdsa.PublicKey (P-256) for ES256.
+type JWTKeySet map[string]interface{}
+
+// JWTConfig represents the configuration of the Bearer token authentication middleware.
+type JWTConfig struct {
+	// Keys are the keys used to verify the token signatures.
+	Keys JWTKeySet
+	// Algorithms are the allowed signing algorithms. Defaults to HS256, RS256 and ES256.
+	Algorithms []string
+	// Issuer is the required "iss" claim. The issuer is not checked if it is empty.
+	Issuer string
+	// Audience is the audience that the "aud" claim must contain. The audience is not checked if it is empty.
+	Audience string
+	// ClockSkew is the tolerance applied to the "exp", "nbf" and "iat" claims.
+	ClockSkew time.Duration
+	// RequireExpiry rejects the tokens without an "exp" claim. Defaults to true if it is nil.
+	RequireExpiry *bool
+}
+
+// JWTClaims are the claims of a validated JWT.
+type JWTClaims map[string]interface{}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// jsonWebKey is a JSON Web Key of a JWKS file.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv"`
+	K   string `json:"k"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// BearerAuth is a gin middleware for validating the JWT Bearer token of the request.
+// The method writes a Principal with the subject, the "roles" claim and the claims as attributes, and the claims
+// as the JWTClaimsKey to the gin context. The method returns an error if the token is not set or is not valid.
+func BearerAuth(cnf JWTConfig) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, err := GetBearerTokenFromHeader(ctx)
+		if err != nil {
+			bearerAuthError(ctx, BearerAuthRequiredErrMsg)
+			return
+		}
+		claims, err := ParseJWT(token, cnf)
+		if err != nil {
+			logger.Error(jwtValidationErrMsg, err)
+			bearerAuthError(ctx, BearerAuthFailedErrMsg, `error="invalid_token"`)
+			return
+		}
+		SetPrincipal(ctx, &Principal{
+			Username:   claims.Subject(),
+			AuthMethod: BearerAuthMethod,
+			Roles:      claims.Strings(RolesClaim),
+			Attributes: claims,
+		})
+		ctx.Set(JWTClaimsKey, claims)
+		ctx.Next()
+	}
+}
+
+// GetBearerTokenFromHeader gets the Bearer token from the Authorization header.
+func GetBearerTokenFromHeader(ctx *gin.Context) (string, error) {
+	auth := strings.SplitN(ctx.Request.Header.Get(AuthorizationHeaderKey), " ", 2)
+	if len(auth) != 2 || !strings.EqualFold(auth[0], BearerAuthScheme) || strings.TrimSpace(auth[1]) == "" {
+		return "", errors.New(BearerAuthRequiredErrMsg)
+	}
+	return strings.TrimSpace(auth[1]), nil
+}
+
+// GetJWTClaims returns the claims of the request that were set by the BearerAuth middleware.
+func GetJWTClaims(ctx *gin.Context) (JWTClaims, bool) {
+	claims, ok := ctx.Get(JWTClaimsKey)
+	if !ok {
+		return nil, false
+	}
+	c, ok := claims.(JWTClaims)
+	return c, ok
+}
+
+// ParseJWT verifies the signature and the registered claims of the token and returns its claims.
+func ParseJWT(token string, cnf JWTConfig) (JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New(jwtMalformedErrMsg)
+	}
+	header := new(jwtHeader)
+	if err := decodeJWTSegment(parts[0], header); err != nil {
+		return nil, err
+	}
+	if !cnf.allows(header.Alg) {
+		return nil, errors.Newf(jwtAlgorithmErrMsg, header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New(jwtMalformedErrMsg)
+	}
+	key, ok := cnf.Keys.key(header.Kid, header.Alg)
+	if !ok {
+		return nil, errors.Newf(jwtKeyNotFoundErrMsg, header.Kid, header.Alg)
+	}
+	if !verifyJWTSignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, errors.New(jwtSignatureErrMsg)
+	}
+	claims := make(JWTClaims)
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if err := claims.validate(cnf, time.Now()); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// LoadJWKSFile loads the RSA, EC and symmetric keys of a JSON Web Key Set file into a JWTKeySet.
+func LoadJWKSFile(filePath string) (JWTKeySet, error) {
+	jwks := new(struct {
+		Keys []jsonWebKey `json:"keys"`
+	})
+	if err := fileutils.ReadJsonFile(filePath, jwks); err != nil {
+		return nil, err
+	}
+	keys := make(JWTKeySet, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		key, err := parseJWK(jwk)
+		if err != nil {
+			return nil, err
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+// Subject returns the "sub" claim.
+func (c JWTClaims) Subject() string {
+	return c.String("sub")
+}
+
+// Issuer returns the "iss" claim.
+func (c JWTClaims) Issuer() string {
+	return c.String("iss")
+}
+
+// Audience returns the "aud" claim, which can be a single value or a list.
+func (c JWTClaims) Audience() []string {
+	return c.Strings("aud")
+}
+
+// String returns a string claim.
+func (c JWTClaims) String(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// Strings returns a claim that is a list of strings or a single string.
+func (c JWTClaims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// Time returns a claim that is a NumericDate.
+func (c JWTClaims) Time(name string) (time.Time, bool) {
+	v, ok := c[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	sec, frac := math.Modf(v)
+	return time.Unix(int64(sec), int64(frac*float64(time.Second))), true
+}
+
+// timeClaim returns a claim that is a NumericDate and whether the claim is set.
+// The method returns an error if the claim is set and is not a NumericDate.
+func (c JWTClaims) timeClaim(name string) (time.Time, bool, error) {
+	if _, ok := c[name]; !ok {
+		return time.Time{}, false, nil
+	}
+	t, ok := c.Time(name)
+	if !ok {
+		return time.Time{}, false, errors.Newf(jwtTimeClaimErrMsg, name)
+	}
+	return t, true, nil
+}
+
+// validate checks the time claims, the issuer and the audience of the token.
+func (c JWTClaims) validate(cnf JWTConfig, now time.Time) error {
+	exp, ok, err := c.timeClaim("exp")
+	if err != nil {
+		return err
+	}
+	if !ok && (cnf.RequireExpiry == nil || *cnf.RequireExpiry) {
+		return errors.New(jwtExpiryRequiredErrMsg)
+	}
+	if ok && !now.Before(exp.Add(cnf.ClockSkew)) {
+		return errors.New(jwtExpiredErrMsg)
+	}
+	nbf, ok, err := c.timeClaim("nbf")
+	if err != nil {
+		return err
+	}
+	if ok && now.Before(nbf.Add(-cnf.ClockSkew)) {
+		return errors.New(jwtNotValidYetErrMsg)
+	}
+	iat, ok, err := c.timeClaim("iat")
+	if err != nil {
+		return err
+	}
+	if ok && now.Before(iat.Add(-cnf.ClockSkew)) {
+		return errors.New(jwtIssuedInFutureErrMsg)
+	}
+	if cnf.Issuer != "" && c.Issuer() != cnf.Issuer {
+		return errors.Newf(jwtIssuerErrMsg, c.Issuer())
+	}
+	if cnf.Audience != "" && !slice.EntryExists(c.Audience(), cnf.Audience) {
+		return errors.New(jwtAudienceErrMsg)
+	}
+	return nil
+}
+
+// allows checks if the algorithm is allowed by the configuration.
+func (cnf JWTConfig) allows(alg string) bool {
+	algorithms := cnf.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = []string{HS256, RS256, ES256}
+	}
+	return slice.EntryExists(algorithms, alg)
+}
+
+// key returns the key of the key id, or the default key, if its type matches the algorithm.
+func (ks JWTKeySet) key(kid, alg string) (interface{}, bool) {
+	key, ok := ks[kid]
+	if !ok {
+		key, ok = ks[""]
+	}
+	if !ok {
+		return nil, false
+	}
+	switch key.(type) {
+	case []byte:
+		return key, alg == HS256
+	case *rsa.PublicKey:
+		return key, alg == RS256
+	case *ecdsa.PublicKey:
+		return key, alg == ES256
+	}
+	return nil, false
+}
+
+// verifyJWTSignature verifies the signature of the signing input with the key.
+func verifyJWTSignature(alg string, key interface{}, input, signature []byte) bool {
+	hash := sha256.Sum256(input)
+	switch alg {
+	case HS256:
+		mac := hmac.New(sha256.New, key.([]byte))
+		mac.Write(input)
+		return hmac.Equal(mac.Sum(nil), signature)
+	case RS256:
+		return rsa.VerifyPKCS1v15(key.(*rsa.PublicKey), crypto.SHA256, hash[:], signature) == nil
+	case ES256:
+		if len(signature) != 64 {
+			return false
+		}
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(key.(*ecdsa.PublicKey), hash[:], r, s)
+	}
+	return false
+}
+
+// decodeJWTSegment decodes a base64url encoded json segment of a token into v.
+func decodeJWTSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errors.New(jwtMalformedErrMsg)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return errors.New(jwtMalformedErrMsg)
+	}
+	return nil
+}
+
+// parseJWK parses a RSA, EC (P-256) or symmetric JSON Web Key.
+func parseJWK(jwk jsonWebKey) (interface{}, error) {
+	decode := func(value string) []byte {
+		b, _ := base64.RawURLEncoding.DecodeString(value)
+		return b
+	}
+	switch jwk.Kty {
+	case "oct":
+		if k := decode(jwk.K); len(k) > 0 {
+			return k, nil
+		}
+		return nil, errors.Newf(jwksKeyErrMsg, jwk.Kid, "missing secret")
+	case "RSA":
+		n, e := decode(jwk.N), decode(jwk.E)
+		if len(n) == 0 || len(e) == 0 {
+			return nil, errors.Newf(jwksKeyErrMsg, jwk.Kid, "missing modulus or exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if jwk.Crv != elliptic.P256().Params().Name {
+			return nil, errors.Newf(jwksUnsupportedKeyErrMsg, jwk.Kty+" "+jwk.Crv, jwk.Kid)
+		}
+		x, y := new(big.Int).SetBytes(decode(jwk.X)), new(big.Int).SetBytes(decode(jwk.Y))
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, errors.Newf(jwksKeyErrMsg, jwk.Kid, "point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	}
+	return nil, errors.Newf(jwksUnsupportedKeyErrMsg, jwk.Kty, jwk.Kid)
+}
+
+// bearerAuthError writes an unauthorized error and the WWW-Authenticate header with the Bearer authentication
+// scheme to the gin context.
+func bearerAuthError(ctx *gin.Context, msg string, params ...string) {
+	err := errors.UnauthorizedError(msg)
+	setWWWAuthenticate(ctx, BearerAuthScheme, params...)
+	abortWithError(ctx, err, RestErrMsg{Error: err.Message})
+	logger.Info(err.Message)
+}
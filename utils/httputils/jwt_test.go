@@ -0,0 +1,244 @@
+package httputils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var (
+	jwtSecret = []byte("some-secret")
+)
+
+// signJWT returns a token with the claims signed with the key for the algorithm.
+func signJWT(t *testing.T, alg, kid string, key interface{}, claims map[string]interface{}) string {
+	encode := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		assert.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	input := encode(map[string]string{"alg": alg, "typ": "JWT", "kid": kid}) + "." + encode(claims)
+	hash := sha256.Sum256([]byte(input))
+
+	var signature []byte
+	switch alg {
+	case HS256:
+		mac := hmac.New(sha256.New, key.([]byte))
+		mac.Write([]byte(input))
+		signature = mac.Sum(nil)
+	case RS256:
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, hash[:])
+		assert.NoError(t, err)
+	case ES256:
+		r, s, err := ecdsa.Sign(rand.Reader, key.(*ecdsa.PrivateKey), hash[:])
+		assert.NoError(t, err)
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validClaims() map[string]interface{} {
+	now := time.Now()
+	return map[string]interface{}{
+		"sub": "admin",
+		"iss": "https://issuer.example.com",
+		"aud": []string{"api", "other"},
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+}
+
+func TestParseJWT(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	cnf := JWTConfig{
+		Keys:      JWTKeySet{"": jwtSecret, "rsa": &rsaKey.PublicKey, "ec": &ecKey.PublicKey},
+		Issuer:    "https://issuer.example.com",
+		Audience:  "api",
+		ClockSkew: time.Minute,
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		for _, token := range []string{
+			signJWT(t, HS256, "", jwtSecret, validClaims()),
+			signJWT(t, RS256, "rsa", rsaKey, validClaims()),
+			signJWT(t, ES256, "ec", ecKey, validClaims()),
+		} {
+			claims, err := ParseJWT(token, cnf)
+			assert.NoError(t, err)
+			assert.Equal(t, "admin", claims.Subject())
+			assert.Equal(t, []string{"api", "other"}, claims.Audience())
+		}
+	})
+
+	t.Run("signature", func(t *testing.T) {
+		_, err := ParseJWT(signJWT(t, HS256, "", []byte("other"), validClaims()), cnf)
+		assert.EqualError(t, err, jwtSignatureErrMsg)
+
+		// a HS256 token signed with a public key must not be accepted
+		_, err = ParseJWT(signJWT(t, HS256, "rsa", []byte("secret"), validClaims()), cnf)
+		assert.EqualError(t, err, fmt.Sprintf(jwtKeyNotFoundErrMsg, "rsa", HS256))
+
+		_, err = ParseJWT(signJWT(t, "none", "", jwtSecret, validClaims()), cnf)
+		assert.EqualError(t, err, fmt.Sprintf(jwtAlgorithmErrMsg, "none"))
+
+		_, err = ParseJWT("not.a-token", cnf)
+		assert.EqualError(t, err, jwtMalformedErrMsg)
+	})
+
+	t.Run("time claims", func(t *testing.T) {
+		claims := validClaims()
+		claims["exp"] = time.Now().Add(-30 * time.Second).Unix()
+		_, err := ParseJWT(signJWT(t, HS256, "", jwtSecret, claims), cnf)
+		assert.NoError(t, err)
+
+		claims["exp"] = time.Now().Add(-2 * time.Minute).Unix()
+		_, err = ParseJWT(signJWT(t, HS256, "", jwtSecret, claims), cnf)
+		assert.EqualError(t, err, jwtExpiredErrMsg)
+
+		claims = validClaims()
+		claims["nbf"] = time.Now().Add(2 * time.Minute).Unix()
+		_, err = ParseJWT(signJWT(t, HS256, "", jwtSecret, claims), cnf)
+		assert.EqualError(t, err, jwtNotValidYetErrMsg)
+
+		claims = validClaims()
+		claims["iat"] = time.Now().Add(2 * time.Minute).Unix()
+		_, err = ParseJWT(signJWT(t, HS256, "", jwtSecret, claims), cnf)
+		assert.EqualError(t, err, jwtIssuedInFutureErrMsg)
+
+		// the time claims of another type are rejected
+		for _, name := range []string{"exp", "nbf", "iat"} {
+			for _, value := range []interface{}{"9999999999", nil} {
+				claims = validClaims()
+				claims[name] = value
+				_, err = ParseJWT(signJWT(t, HS256, "", jwtSecret, claims), cnf)
+				assert.EqualError(t, err, fmt.Sprintf(jwtTimeClaimErrMsg, name))
+			}
+		}
+	})
+
+	t.Run("require expiry", func(t *testing.T) {
+		claims := validClaims()
+		delete(claims, "exp")
+		_, err := ParseJWT(signJWT(t, HS256, "", jwtSecret, claims), cnf)
+		assert.EqualError(t, err, jwtExpiryRequiredErrMsg)
+
+		requireExpiry := false
+		optional := cnf
+		optional.RequireExpiry = &requireExpiry
+		_, err = ParseJWT(signJWT(t, HS256, "", jwtSecret, claims), optional)
+		assert.NoError(t, err)
+	})
+
+	t.Run("issuer and audience", func(t *testing.T) {
+		claims := validClaims()
+		claims["iss"] = "other"
+		_, err := ParseJWT(signJWT(t, HS256, "", jwtSecret, claims), cnf)
+		assert.EqualError(t, err, fmt.Sprintf(jwtIssuerErrMsg, "other"))
+
+		claims = validClaims()
+		claims["aud"] = "other"
+		_, err = ParseJWT(signJWT(t, HS256, "", jwtSecret, claims), cnf)
+		assert.EqualError(t, err, jwtAudienceErrMsg)
+	})
+
+	t.Run("algorithms", func(t *testing.T) {
+		cnf := cnf
+		cnf.Algorithms = []string{RS256}
+		_, err := ParseJWT(signJWT(t, HS256, "", jwtSecret, validClaims()), cnf)
+		assert.EqualError(t, err, fmt.Sprintf(jwtAlgorithmErrMsg, HS256))
+	})
+}
+
+func TestLoadJWKSFile(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwks := map[string]interface{}{
+		"keys": []map[string]interface{}{
+			{"kty": "RSA", "kid": "rsa", "use": "sig", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecKey.X.Bytes()), "y": b64(ecKey.Y.Bytes()), "key_ops": []string{"verify"}},
+			{"kty": "oct", "kid": "hmac", "k": b64(jwtSecret)},
+		},
+	}
+	filePath := filepath.Join(t.TempDir(), "jwks.json")
+	data, _ := json.Marshal(jwks)
+	assert.NoError(t, os.WriteFile(filePath, data, 0600))
+
+	keys, err := LoadJWKSFile(filePath)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 3)
+
+	cnf := JWTConfig{Keys: keys}
+	for _, token := range []string{
+		signJWT(t, RS256, "rsa", rsaKey, validClaims()),
+		signJWT(t, ES256, "ec", ecKey, validClaims()),
+		signJWT(t, HS256, "hmac", jwtSecret, validClaims()),
+	} {
+		_, err := ParseJWT(token, cnf)
+		assert.NoError(t, err)
+	}
+
+	data = []byte(`{"keys":[{"kty":"EC","kid":"ec","crv":"P-384"}]}`)
+	assert.NoError(t, os.WriteFile(filePath, data, 0600))
+	_, err = LoadJWKSFile(filePath)
+	assert.EqualError(t, err, fmt.Sprintf(jwksUnsupportedKeyErrMsg, "EC P-384", "ec"))
+
+	_, err = LoadJWKSFile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestBearerAuth(t *testing.T) {
+	router := setupMockRouter(BearerAuth(JWTConfig{Keys: JWTKeySet{"": jwtSecret}}))
+	router.GET("/claims", func(ctx *gin.Context) {
+		claims, ok := GetJWTClaims(ctx)
+		assert.True(t, ok)
+		ctx.String(http.StatusOK, ctx.GetString(AuthUserKey)+":"+claims.Issuer())
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/claims", nil)
+	req.Header.Set(AuthorizationHeaderKey, "Bearer "+signJWT(t, HS256, "", jwtSecret, validClaims()))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "admin:https://issuer.example.com", w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/login", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `{"error":"`+BearerAuthRequiredErrMsg+`"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/login", nil)
+	req.Header.Set(AuthorizationHeaderKey, "Bearer "+signJWT(t, HS256, "", []byte("other"), validClaims()))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `{"error":"`+BearerAuthFailedErrMsg+`"}`, w.Body.String())
+}
@@ -0,0 +1,204 @@
+package httputils
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/dateutils"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	MetricsPath                = "/metrics"
+	PrometheusTextMIMEType     = "text/plain; version=0.0.4; charset=utf-8"
+	unmatchedRouteLabel        = "unmatched"
+	requestsTotalMetricName    = "http_requests_total"
+	requestDurationMetricName  = "http_request_duration_seconds"
+	requestsInFlightMetricName = "http_requests_in_flight"
+)
+
+var (
+	// DefaultLatencyBuckets are the default histogram buckets (in seconds) used for the request latency metric.
+	DefaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+)
+
+// Metrics collects RED (rate, errors, duration) metrics for the requests served by a gin router and exposes them
+// in the Prometheus text exposition format.
+// Metrics are opt-in: use Register to add the middleware and the /metrics endpoint to a router.
+type Metrics struct {
+	namespace string
+	buckets   []float64
+	inFlight  int64
+
+	mu       sync.Mutex
+	requests map[requestLabels]uint64
+	latency  map[requestLabels]*histogram
+}
+
+// requestLabels represents the label set of a single request metric series.
+type requestLabels struct {
+	method string
+	route  string
+	status string
+}
+
+// histogram is a cumulative latency histogram for a single label set.
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewMetrics returns a new Metrics collector. The namespace is prepended to the metric names if it is not empty.
+// If no buckets are provided DefaultLatencyBuckets are used.
+func NewMetrics(namespace string, buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	b := make([]float64, len(buckets))
+	copy(b, buckets)
+	sort.Float64s(b)
+	return &Metrics{
+		namespace: namespace,
+		buckets:   b,
+		requests:  make(map[requestLabels]uint64),
+		latency:   make(map[requestLabels]*histogram),
+	}
+}
+
+// Register adds the metrics middleware to the router and configures the /metrics endpoint.
+// Register should be called before any routes are added to the router so that all the routes are measured.
+func (m *Metrics) Register(r *gin.Engine) {
+	r.Use(m.Middleware())
+	r.GET(MetricsPath, m.Handler)
+}
+
+// Middleware returns a gin middleware that records the request count, the request latency and the number of
+// in-flight requests labelled by the request method, the route template and the response status.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := dateutils.GetDateTimeNow()
+		atomic.AddInt64(&m.inFlight, 1)
+		defer atomic.AddInt64(&m.inFlight, -1)
+
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRouteLabel
+		}
+		m.observe(requestLabels{
+			method: ctx.Request.Method,
+			route:  route,
+			status: strconv.Itoa(ctx.Writer.Status()),
+		}, dateutils.GetDateTimeNow().Sub(start).Seconds())
+	}
+}
+
+// Handler writes the collected metrics to the response in the Prometheus text exposition format.
+func (m *Metrics) Handler(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, PrometheusTextMIMEType, []byte(m.String()))
+}
+
+// observe records a single request.
+func (m *Metrics) observe(labels requestLabels, seconds float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[labels]++
+
+	h, ok := m.latency[labels]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.latency[labels] = h
+	}
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// String returns the collected metrics in the Prometheus text exposition format.
+func (m *Metrics) String() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sb strings.Builder
+	keys := m.sortedLabels()
+
+	name := m.metricName(requestsTotalMetricName)
+	fmt.Fprintf(&sb, "# HELP %s Total number of HTTP requests.\n", name)
+	fmt.Fprintf(&sb, "# TYPE %s counter\n", name)
+	for _, l := range keys {
+		fmt.Fprintf(&sb, "%s{%s} %d\n", name, l.format(), m.requests[l])
+	}
+
+	name = m.metricName(requestDurationMetricName)
+	fmt.Fprintf(&sb, "# HELP %s HTTP request latency in seconds.\n", name)
+	fmt.Fprintf(&sb, "# TYPE %s histogram\n", name)
+	for _, l := range keys {
+		h := m.latency[l]
+		for i, bound := range m.buckets {
+			fmt.Fprintf(&sb, "%s_bucket{%s,le=\"%s\"} %d\n", name, l.format(), formatFloat(bound), h.counts[i])
+		}
+		fmt.Fprintf(&sb, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, l.format(), h.count)
+		fmt.Fprintf(&sb, "%s_sum{%s} %s\n", name, l.format(), formatFloat(h.sum))
+		fmt.Fprintf(&sb, "%s_count{%s} %d\n", name, l.format(), h.count)
+	}
+
+	name = m.metricName(requestsInFlightMetricName)
+	fmt.Fprintf(&sb, "# HELP %s Number of HTTP requests currently being served.\n", name)
+	fmt.Fprintf(&sb, "# TYPE %s gauge\n", name)
+	fmt.Fprintf(&sb, "%s %d\n", name, atomic.LoadInt64(&m.inFlight))
+
+	return sb.String()
+}
+
+// sortedLabels returns the recorded label sets in a stable order so that the output is deterministic.
+func (m *Metrics) sortedLabels() []requestLabels {
+	keys := make([]requestLabels, 0, len(m.requests))
+	for l := range m.requests {
+		keys = append(keys, l)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].status < keys[j].status
+	})
+	return keys
+}
+
+// metricName prepends the namespace to the metric name.
+func (m *Metrics) metricName(name string) string {
+	if m.namespace == "" {
+		return name
+	}
+	return m.namespace + "_" + name
+}
+
+// format returns the label set in the Prometheus label format.
+func (l requestLabels) format() string {
+	return fmt.Sprintf("method=\"%s\",route=\"%s\",status=\"%s\"",
+		escapeLabelValue(l.method), escapeLabelValue(l.route), escapeLabelValue(l.status))
+}
+
+// escapeLabelValue escapes a label value as required by the Prometheus text exposition format.
+func escapeLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+// formatFloat formats a float value as required by the Prometheus text exposition format.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
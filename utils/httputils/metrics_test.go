@@ -0,0 +1,55 @@
+package httputils
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics_Register(t *testing.T) {
+	m := NewMetrics("")
+	r := NewRouter()
+	m.Register(r)
+	r.GET("/users/:id", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "OK")
+	})
+
+	for _, path := range []string{"/users/1", "/users/2", notFoundApiPath} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		r.ServeHTTP(w, req)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, MetricsPath, nil)
+	r.ServeHTTP(w, req)
+	output := readResponseBody(w.Body, t)
+	t.Logf("output = %s", output)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, PrometheusTextMIMEType, w.Header().Get(ContentTypeHeaderKey))
+	assert.True(t, strings.Contains(output, `http_requests_total{method="GET",route="/users/:id",status="200"} 2`))
+	assert.True(t, strings.Contains(output, `http_requests_total{method="GET",route="unmatched",status="404"} 1`))
+	assert.True(t, strings.Contains(output, `http_request_duration_seconds_bucket{method="GET",route="/users/:id",status="200",le="+Inf"} 2`))
+	assert.True(t, strings.Contains(output, `http_request_duration_seconds_count{method="GET",route="/users/:id",status="200"} 2`))
+	assert.True(t, strings.Contains(output, "http_requests_in_flight 1"))
+}
+
+func TestMetrics_String(t *testing.T) {
+	m := NewMetrics("api", 0.1, 1)
+	m.observe(requestLabels{method: http.MethodPost, route: "/items", status: "201"}, 0.5)
+
+	output := m.String()
+	assert.True(t, strings.Contains(output, "# TYPE api_http_requests_total counter"))
+	assert.True(t, strings.Contains(output, `api_http_request_duration_seconds_bucket{method="POST",route="/items",status="201",le="0.1"} 0`))
+	assert.True(t, strings.Contains(output, `api_http_request_duration_seconds_bucket{method="POST",route="/items",status="201",le="1"} 1`))
+	assert.True(t, strings.Contains(output, `api_http_request_duration_seconds_sum{method="POST",route="/items",status="201"} 0.5`))
+	assert.True(t, strings.Contains(output, "api_http_requests_in_flight 0"))
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	assert.Equal(t, `a\"b\\c\n`, escapeLabelValue("a\"b\\c\n"))
+}
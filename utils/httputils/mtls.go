@@ -0,0 +1,110 @@
+package httputils
+
+import (
+	"crypto/x509"
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/privatesquare/bkst-go-utils/utils/logger"
+	"path"
+)
+
+const (
+	ClientCertAuthMethod      = "mtls"
+	ClientCertRequiredErrMsg  = "401 unauthorized: a verified client certificate is required"
+	ClientCertForbiddenErrMsg = "Client certificate is not authorized"
+	clientCertRejectedLogMsg  = "Client certificate '%s' does not match a rule"
+)
+
+// ClientCertRule maps the client certificates that match the rule to a principal.
+// The non-empty fields must all match. The patterns support the path.Match syntax, for example
+// "*.billing.svc.cluster.local" or "spiffe://cluster/ns/billing/*".
+type ClientCertRule struct {
+	// CommonName is the pattern of the subject common name.
+	CommonName string
+	// DNSName is the pattern of one of the DNS subject alternative names.
+	DNSName string
+	// URI is the pattern of one of the URI subject alternative names, for example a SPIFFE ID.
+	URI string
+	// Email is the pattern of one of the email subject alternative names.
+	Email string
+	// Username is the username of the principal. Defaults to the subject common name.
+	Username string
+	// Roles are the roles of the principal.
+	Roles []string
+}
+
+// ClientCertConfig represents the configuration of the client certificate authentication middleware.
+type ClientCertConfig struct {
+	// Rules are checked in order, the first matching rule maps the certificate to a principal.
+	Rules []ClientCertRule
+}
+
+// ClientCertAuth is a gin middleware for mutual TLS authentication. The middleware reads the client certificate
+// that was verified by the TLS server, see config.TLSConfig, and maps it to a Principal with the first matching
+// rule. The method writes the Principal to the gin context.
+// The method returns an unauthorized error if there is no verified client certificate and a forbidden error if
+// no rule matches the certificate.
+func ClientCertAuth(cnf ClientCertConfig) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.Request.TLS == nil || len(ctx.Request.TLS.VerifiedChains) == 0 ||
+			len(ctx.Request.TLS.VerifiedChains[0]) == 0 {
+			err := errors.UnauthorizedError(ClientCertRequiredErrMsg)
+			abortWithError(ctx, err, RestErrMsg{Error: err.Message})
+			logger.Info(err.Message)
+			return
+		}
+		cert := ctx.Request.TLS.VerifiedChains[0][0]
+		for _, rule := range cnf.Rules {
+			if rule.matches(cert) {
+				SetPrincipal(ctx, rule.principal(cert))
+				ctx.Next()
+				return
+			}
+		}
+		logger.Infof(clientCertRejectedLogMsg, cert.Subject.String())
+		AbortWithRestErr(ctx, errors.ForbiddenError(ClientCertForbiddenErrMsg))
+	}
+}
+
+// matches checks if all the patterns of the rule match the certificate.
+func (r ClientCertRule) matches(cert *x509.Certificate) bool {
+	uris := make([]string, len(cert.URIs))
+	for i, u := range cert.URIs {
+		uris[i] = u.String()
+	}
+	return matchPattern(r.CommonName, cert.Subject.CommonName) &&
+		matchPattern(r.DNSName, cert.DNSNames...) &&
+		matchPattern(r.URI, uris...) &&
+		matchPattern(r.Email, cert.EmailAddresses...)
+}
+
+// principal returns the principal of the certificate.
+func (r ClientCertRule) principal(cert *x509.Certificate) *Principal {
+	username := r.Username
+	if username == "" {
+		username = cert.Subject.CommonName
+	}
+	return &Principal{
+		Username:   username,
+		AuthMethod: ClientCertAuthMethod,
+		Roles:      append([]string(nil), r.Roles...),
+		Attributes: map[string]interface{}{
+			"subject": cert.Subject.String(),
+			"issuer":  cert.Issuer.String(),
+			"serial":  cert.SerialNumber.String(),
+		},
+	}
+}
+
+// matchPattern checks if one of the values matches the pattern. An empty pattern matches any value.
+func matchPattern(pattern string, values ...string) bool {
+	if pattern == "" {
+		return true
+	}
+	for _, v := range values {
+		if ok, _ := path.Match(pattern, v); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package httputils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/config"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCert is a generated certificate and its key.
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+// newTestCert returns a certificate signed by the parent, or a self-signed CA certificate if parent is nil.
+func newTestCert(t *testing.T, template *x509.Certificate, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	signerCert, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		signerCert, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+// tlsCertificate returns the certificate as a tls.Certificate.
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+// writePEM writes the certificate and its key to PEM files in the directory.
+func (c *testCert) writePEM(t *testing.T, dir, name string) (string, string) {
+	keyDer, err := x509.MarshalECPrivateKey(c.key)
+	assert.NoError(t, err)
+	certFile, keyFile := filepath.Join(dir, name+".pem"), filepath.Join(dir, name+"-key.pem")
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certFile, keyFile
+}
+
+func clientCertRules() ClientCertConfig {
+	return ClientCertConfig{Rules: []ClientCertRule{
+		{URI: "spiffe://cluster/ns/billing/*", Username: "billing", Roles: []string{"billing"}},
+		{CommonName: "reports", DNSName: "*.reports.svc"},
+	}}
+}
+
+func TestClientCertAuth(t *testing.T) {
+	ca := newTestCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "test-ca"}}, nil)
+	spiffeID, _ := url.Parse("spiffe://cluster/ns/billing/sa")
+	billing := newTestCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "client"}, URIs: []*url.URL{spiffeID}}, ca)
+	reports := newTestCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "reports"}, DNSNames: []string{"api.reports.svc"}}, ca)
+	unknown := newTestCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "reports"}, DNSNames: []string{"api.other.svc"}}, ca)
+
+	router := setupMockRouter(ClientCertAuth(clientCertRules()))
+	router.GET("/principal", func(ctx *gin.Context) {
+		p, _ := GetPrincipal(ctx)
+		ctx.String(http.StatusOK, p.Username+":"+p.AuthMethod+":"+p.Attributes["subject"].(string))
+	})
+
+	request := func(cert *testCert) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/principal", nil)
+		if cert != nil {
+			req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert.cert, ca.cert}}}
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request(billing)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "billing:mtls:CN=client", w.Body.String())
+
+	w = request(reports)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "reports:mtls:CN=reports", w.Body.String())
+
+	w = request(unknown)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, `{"message":"Client certificate is not authorized","status":403,"error":"Forbidden"}`, w.Body.String())
+
+	w = request(nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `{"error":"`+ClientCertRequiredErrMsg+`"}`, w.Body.String())
+}
+
+func TestClientCertAuth_TLSServer(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "test-ca"}}, nil)
+	server := newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "localhost"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+	client := newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "reports"},
+		DNSNames:    []string{"api.reports.svc"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+	caFile, _ := ca.writePEM(t, dir, "ca")
+	certFile, keyFile := server.writePEM(t, dir, "server")
+
+	cnf := config.ServerConfig{TLS: config.TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile}}
+	tlsCnf, err := cnf.TLSConfig()
+	assert.NoError(t, err)
+
+	router := setupMockRouter(ClientCertAuth(clientCertRules()))
+	ts := httptest.NewUnstartedServer(router)
+	ts.TLS = tlsCnf
+	ts.StartTLS()
+	defer ts.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      roots,
+		Certificates: []tls.Certificate{client.tlsCertificate()},
+	}}}
+	resp, err := httpClient.Get(ts.URL + "/login")
+	assert.NoError(t, err)
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "OK", string(body))
+
+	// the TLS handshake fails without a client certificate
+	httpClient = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	_, err = httpClient.Get(ts.URL + "/login")
+	assert.Error(t, err)
+}
@@ -0,0 +1,76 @@
+package httputils
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/logger"
+	"github.com/privatesquare/bkst-go-utils/utils/slice"
+)
+
+const (
+	AuthPrincipalKey         = "principal"
+	WWWAuthenticateHeaderKey = "WWW-Authenticate"
+	BasicAuthMethod          = "basic"
+	BearerAuthMethod         = "bearer"
+	APIKeyAuthMethod         = "apikey"
+)
+
+var (
+	// AuthRealm is the realm of the WWW-Authenticate header of the unauthorized responses.
+	AuthRealm = "Restricted"
+)
+
+// principalContextKey is the key of the Principal in the request context.
+type principalContextKey struct{}
+
+// Principal represents an authenticated user or client. The credentials used for the authentication are
+// never stored in the Principal.
+type Principal struct {
+	Username   string                 `json:"username"`
+	AuthMethod string                 `json:"auth_method"`
+	Roles      []string               `json:"roles,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// SetPrincipal writes the principal to the gin context and to the request context.
+// The username of the principal is also written as the AuthUserKey to the gin context and is added as the user
+// field to the logger of the request context, see logger.FromContext.
+func SetPrincipal(ctx *gin.Context, p *Principal) {
+	ctx.Set(AuthPrincipalKey, p)
+	ctx.Set(AuthUserKey, p.Username)
+	reqCtx := context.WithValue(ctx.Request.Context(), principalContextKey{}, p)
+	reqCtx = logger.ContextWithUser(reqCtx, p.Username)
+	ctx.Request = ctx.Request.WithContext(reqCtx)
+}
+
+// GetPrincipal returns the principal of the request that was set by an authentication middleware.
+func GetPrincipal(ctx *gin.Context) (*Principal, bool) {
+	v, ok := ctx.Get(AuthPrincipalKey)
+	if !ok {
+		return nil, false
+	}
+	p, ok := v.(*Principal)
+	return p, ok
+}
+
+// PrincipalFromContext returns the principal of a request context, for example in the services called by a
+// handler with ctx.Request.Context().
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return p, ok
+}
+
+// HasRole checks if the principal has the role.
+func (p *Principal) HasRole(role string) bool {
+	return slice.EntryExists(p.Roles, role)
+}
+
+// setWWWAuthenticate sets the WWW-Authenticate header of an unauthorized response for the authentication scheme.
+// The params are added after the realm, for example `error="invalid_token"`.
+func setWWWAuthenticate(ctx *gin.Context, scheme string, params ...string) {
+	value := scheme + ` realm="` + AuthRealm + `"`
+	for _, param := range params {
+		value += ", " + param
+	}
+	ctx.Header(WWWAuthenticateHeaderKey, value)
+}
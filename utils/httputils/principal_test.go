@@ -0,0 +1,119 @@
+package httputils
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPrincipal_BasicAuth(t *testing.T) {
+	router := setupMockRouter(BasicAuth(getAccount()))
+	router.GET("/principal", func(ctx *gin.Context) {
+		p, ok := GetPrincipal(ctx)
+		assert.True(t, ok)
+		rp, ok := PrincipalFromContext(ctx.Request.Context())
+		assert.True(t, ok)
+		assert.Same(t, p, rp)
+		_, exists := ctx.Get(AuthPassKey)
+		assert.False(t, exists)
+		ctx.JSON(http.StatusOK, p)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/principal", nil)
+	req.SetBasicAuth("admin", "admin")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"username":"admin","auth_method":"basic"}`, w.Body.String())
+}
+
+func TestPrincipal_BearerAuth(t *testing.T) {
+	router := setupMockRouter(BearerAuth(JWTConfig{Keys: JWTKeySet{"": jwtSecret}}))
+	router.GET("/principal", func(ctx *gin.Context) {
+		p, ok := GetPrincipal(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, "admin", ctx.GetString(AuthUserKey))
+		assert.Equal(t, BearerAuthMethod, p.AuthMethod)
+		assert.True(t, p.HasRole("admin"))
+		assert.False(t, p.HasRole("other"))
+		assert.Equal(t, "https://issuer.example.com", p.Attributes["iss"])
+		ctx.String(http.StatusOK, "OK")
+	})
+
+	claims := validClaims()
+	claims["roles"] = []string{"admin"}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/principal", nil)
+	req.Header.Set(AuthorizationHeaderKey, "Bearer "+signJWT(t, HS256, "", jwtSecret, claims))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestPrincipal_LoggerContext(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	router := setupMockRouter(func(ctx *gin.Context) {
+		l := logger.NewFromZap(zap.New(core))
+		ctx.Request = ctx.Request.WithContext(logger.NewContext(ctx.Request.Context(), l))
+	})
+	router.Use(BasicAuth(getAccount()))
+	router.GET("/principal", func(ctx *gin.Context) {
+		logger.InfoCtx(ctx, "some handler message")
+		// a new principal replaces the user field
+		SetPrincipal(ctx, &Principal{Username: "other"})
+		logger.InfoCtx(ctx, "some other message")
+		ctx.String(http.StatusOK, "OK")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/principal", nil)
+	req.SetBasicAuth("admin", "admin")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.Equal(t, 2, logs.Len()) {
+		assert.Equal(t, "admin", logs.All()[0].ContextMap()[logger.UserFieldKey])
+		assert.Equal(t, "other", logs.All()[1].ContextMap()[logger.UserFieldKey])
+		assert.Len(t, logs.All()[1].Context, 1)
+	}
+}
+
+func TestPrincipalFromContext(t *testing.T) {
+	_, ok := PrincipalFromContext(context.Background())
+	assert.False(t, ok)
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	_, ok = GetPrincipal(ctx)
+	assert.False(t, ok)
+}
+
+func TestWWWAuthenticate(t *testing.T) {
+	tests := []struct {
+		name       string
+		middleware gin.HandlerFunc
+		auth       string
+		header     string
+	}{
+		{"basic required", BasicAuth(getAccount()), "", `Basic realm="Restricted", charset="UTF-8"`},
+		{"basic failed", BasicAuth(getAccount()), "Basic YWRtaW46b3RoZXI=", `Basic realm="Restricted", charset="UTF-8"`},
+		{"bearer required", BearerAuth(JWTConfig{}), "", `Bearer realm="Restricted"`},
+		{"bearer failed", BearerAuth(JWTConfig{}), "Bearer a.b.c", `Bearer realm="Restricted", error="invalid_token"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupMockRouter(tt.middleware)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/login", nil)
+			if tt.auth != "" {
+				req.Header.Set(AuthorizationHeaderKey, tt.auth)
+			}
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Equal(t, tt.header, w.Header().Get(WWWAuthenticateHeaderKey))
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package httputils
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+)
+
+const (
+	ProblemDetailsKey      = "problemDetails"
+	ProblemJsonMIMEType    = errors.ProblemJsonMIMEType
+	PathNotFoundErrMsg     = "Path Not Found"
+	MethodNotAllowedErrMsg = "Method Not Allowed"
+)
+
+// ProblemDetails is a gin middleware that makes the error responses of the router use the RFC 7807
+// application/problem+json format instead of the default json format.
+func ProblemDetails() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(ProblemDetailsKey, true)
+		ctx.Next()
+	}
+}
+
+// WithProblemDetails is a router option that renders the error responses of the router as RFC 7807 problem details.
+func WithProblemDetails() RouterOption {
+	return func(r *gin.Engine) {
+		r.Use(ProblemDetails())
+	}
+}
+
+// UsesProblemDetails checks if the error responses of the request should be rendered as RFC 7807 problem details.
+func UsesProblemDetails(ctx *gin.Context) bool {
+	return ctx.GetBool(ProblemDetailsKey)
+}
+
+// AbortWithRestErr writes the RestErr to the gin context and aborts the request.
+// The error is rendered as RFC 7807 problem details if the router uses problem details.
+func AbortWithRestErr(ctx *gin.Context, err *errors.RestErr) {
+	abortWithError(ctx, err, err)
+}
+
+// abortWithError writes the error to the gin context as RFC 7807 problem details if the router uses problem details
+// and as the json body otherwise, and aborts the request.
+func abortWithError(ctx *gin.Context, err *errors.RestErr, body interface{}) {
+	if UsesProblemDetails(ctx) {
+		ctx.Header(ContentTypeHeaderKey, ProblemJsonMIMEType)
+		ctx.AbortWithStatusJSON(err.StatusCode, err.Problem(ctx.Request.URL.Path))
+		return
+	}
+	ctx.AbortWithStatusJSON(err.StatusCode, body)
+}
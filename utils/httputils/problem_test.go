@@ -0,0 +1,76 @@
+package httputils
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var (
+	problemNotFoundResponse         = `{"type":"about:blank","title":"Not Found","status":404,"detail":"Path Not Found","instance":"/notFound"}`
+	problemMethodNotAllowedResponse = `{"type":"about:blank","title":"Method Not Allowed","status":405,"detail":"Method Not Allowed","instance":"/health"}`
+	problemNoAuthResponse           = `{"type":"about:blank","title":"Unauthorized","status":401,"detail":"401 unauthorized: Basic authentication is required","instance":"/login"}`
+	problemInvalidAuthResponse      = `{"type":"about:blank","title":"Unauthorized","status":401,"detail":"401 unauthorized: username or password is incorrect","instance":"/login"}`
+)
+
+func TestProblemDetails_NoRoute(t *testing.T) {
+	r := NewRouter(WithProblemDetails())
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, notFoundApiPath, nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, ProblemJsonMIMEType, w.Header().Get(ContentTypeHeaderKey))
+	assert.JSONEq(t, problemNotFoundResponse, readResponseBody(w.Body, t))
+}
+
+func TestProblemDetails_MethodNotAllowed(t *testing.T) {
+	r := NewRouter(WithProblemDetails())
+	r.GET(healthApiPath, Health)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, healthApiPath, nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, ProblemJsonMIMEType, w.Header().Get(ContentTypeHeaderKey))
+	assert.JSONEq(t, problemMethodNotAllowedResponse, readResponseBody(w.Body, t))
+}
+
+func TestProblemDetails_BasicAuth(t *testing.T) {
+	r := NewRouter(WithProblemDetails())
+	r.Use(BasicAuth(getAccount()))
+	r.GET("/login", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "OK")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/login", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, ProblemJsonMIMEType, w.Header().Get(ContentTypeHeaderKey))
+	assert.JSONEq(t, problemNoAuthResponse, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/login", nil)
+	req.SetBasicAuth("admin", "")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, problemInvalidAuthResponse, w.Body.String())
+}
+
+func TestAbortWithRestErr(t *testing.T) {
+	r := NewRouter()
+	r.GET("/conflict", func(ctx *gin.Context) {
+		AbortWithRestErr(ctx, errors.ConflictError("already exists"))
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/conflict", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, `{"message":"already exists","status":409,"error":"Conflict"}`, w.Body.String())
+}
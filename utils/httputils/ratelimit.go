@@ -0,0 +1,187 @@
+package httputils
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/privatesquare/bkst-go-utils/utils/logger"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	RetryAfterHeaderKey        = "Retry-After"
+	RateLimitExceededErrMsg    = "Rate limit exceeded, retry in %d second(s)"
+	rateLimitStoreErrMsg       = "Unable to apply the rate limit, the request is allowed"
+	invalidRateLimitRateErrMsg = "RateLimit requires a rate greater than 0"
+	DefaultRateLimitBucketTTL  = 10 * time.Minute
+)
+
+// RateLimitKeyFunc returns the key that identifies the client a request is rate limited for.
+type RateLimitKeyFunc func(ctx *gin.Context) string
+
+// RateLimitStore stores the token buckets of the rate limited clients.
+// Implement the interface to share the rate limits between multiple instances of a service.
+type RateLimitStore interface {
+	// Take takes a token from the bucket of the key. The bucket is refilled with rate tokens per second and
+	// holds at most burst tokens. The method returns true if a token was taken, otherwise false and the time
+	// to wait until a token is available.
+	Take(key string, rate float64, burst int) (bool, time.Duration, error)
+}
+
+// RateLimitConfig represents the configuration of the rate limit middleware.
+type RateLimitConfig struct {
+	// Rate is the number of requests per second allowed for a key. The rate must be greater than 0.
+	Rate float64
+	// Burst is the maximum number of requests allowed at once for a key. Defaults to 1.
+	Burst int
+	// KeyFunc identifies the client of a request. Defaults to ClientIPKeyFunc.
+	KeyFunc RateLimitKeyFunc
+	// Store holds the token buckets. Defaults to a new MemoryRateLimitStore.
+	Store RateLimitStore
+}
+
+// ClientIPKeyFunc rate limits requests per client IP.
+func ClientIPKeyFunc(ctx *gin.Context) string {
+	return ctx.ClientIP()
+}
+
+// AuthUserKeyFunc rate limits requests per authenticated user. The function falls back to the client IP
+// if the request is not authenticated, so it should be used after an authentication middleware.
+func AuthUserKeyFunc(ctx *gin.Context) string {
+	if user := ctx.GetString(AuthUserKey); user != "" {
+		return AuthUserKey + ":" + user
+	}
+	return ClientIPKeyFunc(ctx)
+}
+
+// RateLimit is a gin middleware that limits the number of requests per client using token buckets.
+// The middleware writes a 429 error with a Retry-After header to the gin context when the limit is exceeded.
+// If the store returns an error the request is allowed and the error is logged.
+// The method panics if the rate is not greater than 0, so that a misconfigured route is noticed when the router
+// is built.
+func RateLimit(cnf RateLimitConfig) gin.HandlerFunc {
+	if cnf.Rate <= 0 {
+		panic(invalidRateLimitRateErrMsg)
+	}
+	if cnf.Burst <= 0 {
+		cnf.Burst = 1
+	}
+	if cnf.KeyFunc == nil {
+		cnf.KeyFunc = ClientIPKeyFunc
+	}
+	if cnf.Store == nil {
+		cnf.Store = NewMemoryRateLimitStore(DefaultRateLimitBucketTTL)
+	}
+	return func(ctx *gin.Context) {
+		ok, wait, err := cnf.Store.Take(cnf.KeyFunc(ctx), cnf.Rate, cnf.Burst)
+		if err != nil {
+			logger.Error(rateLimitStoreErrMsg, err)
+			ctx.Next()
+			return
+		}
+		if !ok {
+			RateLimitExceeded(ctx, wait)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// RateLimitExceeded writes a too many requests error with a Retry-After header to the gin context.
+func RateLimitExceeded(ctx *gin.Context, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	err := errors.TooManyRequestsErrorf(RateLimitExceededErrMsg, seconds)
+	ctx.Header(RetryAfterHeaderKey, strconv.Itoa(seconds))
+	AbortWithRestErr(ctx, err)
+	logger.Info(err.Message)
+}
+
+// MemoryRateLimitStore is an in-memory RateLimitStore.
+// Buckets that have not been used for the ttl duration are evicted once they are refilled, so that an eviction
+// never grants a client more tokens than waiting would.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	ttl       time.Duration
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// tokenBucket represents the state of the bucket of a single key.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	rate   float64
+	burst  int
+}
+
+// full returns true if the bucket is refilled to the burst at the time.
+func (b *tokenBucket) full(now time.Time) bool {
+	return b.tokens+now.Sub(b.last).Seconds()*b.rate >= float64(b.burst)
+}
+
+// NewMemoryRateLimitStore returns a new in-memory RateLimitStore that evicts buckets after the ttl duration.
+func NewMemoryRateLimitStore(ttl time.Duration) *MemoryRateLimitStore {
+	if ttl <= 0 {
+		ttl = DefaultRateLimitBucketTTL
+	}
+	return &MemoryRateLimitStore{
+		buckets:   make(map[string]*tokenBucket),
+		ttl:       ttl,
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Take implements the RateLimitStore interface.
+func (s *MemoryRateLimitStore) Take(key string, rate float64, burst int) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.evict(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(burst), last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	b.rate, b.burst = rate, burst
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+	if rate <= 0 {
+		return false, s.ttl, nil
+	}
+	return false, time.Duration((1 - b.tokens) / rate * float64(time.Second)), nil
+}
+
+// Len returns the number of buckets in the store.
+func (s *MemoryRateLimitStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buckets)
+}
+
+// evict removes the buckets that have not been used for the ttl duration and are refilled.
+// The buckets are checked at most once per ttl duration.
+func (s *MemoryRateLimitStore) evict(now time.Time) {
+	if now.Sub(s.lastSweep) < s.ttl {
+		return
+	}
+	for key, b := range s.buckets {
+		if now.Sub(b.last) >= s.ttl && b.full(now) {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
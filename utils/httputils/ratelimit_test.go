@@ -0,0 +1,146 @@
+package httputils
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var (
+	rateLimitExceededResponse = `{"message":"Rate limit exceeded, retry in 1 second(s)","status":429,"error":"Too Many Requests"}`
+)
+
+type failingRateLimitStore struct{}
+
+func (s failingRateLimitStore) Take(string, float64, int) (bool, time.Duration, error) {
+	return false, 0, errors.New("store unavailable")
+}
+
+func TestRateLimit(t *testing.T) {
+	router := setupMockRouter(RateLimit(RateLimitConfig{Rate: 1, Burst: 2}))
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/login", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/login", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get(RetryAfterHeaderKey))
+	assert.Equal(t, rateLimitExceededResponse, w.Body.String())
+
+	// another client is not limited
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/login", nil)
+	req.RemoteAddr = "10.0.0.2:1234"
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRateLimit_DefaultBurst(t *testing.T) {
+	router := setupMockRouter(RateLimit(RateLimitConfig{Rate: 1}))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/login", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestRateLimit_InvalidRate(t *testing.T) {
+	assert.PanicsWithValue(t, invalidRateLimitRateErrMsg, func() { RateLimit(RateLimitConfig{}) })
+	assert.PanicsWithValue(t, invalidRateLimitRateErrMsg, func() { RateLimit(RateLimitConfig{Rate: -1, Burst: 1}) })
+}
+
+func TestRateLimit_StoreError(t *testing.T) {
+	router := setupMockRouter(RateLimit(RateLimitConfig{Rate: 1, Burst: 1, Store: failingRateLimitStore{}}))
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/login", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAuthUserKeyFunc(t *testing.T) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	ctx.Request.RemoteAddr = "10.0.0.1:1234"
+	assert.Equal(t, "10.0.0.1", AuthUserKeyFunc(ctx))
+
+	ctx.Set(AuthUserKey, "admin")
+	assert.Equal(t, AuthUserKey+":admin", AuthUserKeyFunc(ctx))
+}
+
+func TestMemoryRateLimitStore_Take(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryRateLimitStore(time.Minute)
+	store.now = func() time.Time { return now }
+
+	ok, _, err := store.Take("key", 2, 1)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, wait, err := store.Take("key", 2, 1)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// the bucket is refilled over time
+	now = now.Add(500 * time.Millisecond)
+	ok, _, _ = store.Take("key", 2, 1)
+	assert.True(t, ok)
+}
+
+func TestMemoryRateLimitStore_Evict(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryRateLimitStore(time.Minute)
+	store.now = func() time.Time { return now }
+
+	_, _, _ = store.Take("a", 1, 1)
+	_, _, _ = store.Take("b", 1, 1)
+	assert.Equal(t, 2, store.Len())
+
+	now = now.Add(2 * time.Minute)
+	_, _, _ = store.Take("c", 1, 1)
+	assert.Equal(t, 1, store.Len())
+}
+
+func TestMemoryRateLimitStore_EvictAfterRefill(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryRateLimitStore(time.Minute)
+	store.now = func() time.Time { return now }
+
+	// the bucket takes 10 minutes to refill, longer than the ttl
+	for i := 0; i < 10; i++ {
+		ok, _, _ := store.Take("a", 1.0/60, 10)
+		assert.True(t, ok)
+	}
+	ok, _, _ := store.Take("a", 1.0/60, 10)
+	assert.False(t, ok)
+
+	// the bucket is not evicted before it is refilled
+	now = now.Add(2 * time.Minute)
+	_, _, _ = store.Take("b", 1, 1)
+	assert.Equal(t, 2, store.Len())
+	for i := 0; i < 2; i++ {
+		ok, _, _ = store.Take("a", 1.0/60, 10)
+		assert.True(t, ok)
+	}
+	ok, _, _ = store.Take("a", 1.0/60, 10)
+	assert.False(t, ok)
+
+	// the refilled bucket is evicted
+	now = now.Add(20 * time.Minute)
+	_, _, _ = store.Take("b", 1, 1)
+	assert.Equal(t, 1, store.Len())
+}
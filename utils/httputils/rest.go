@@ -1,6 +1,7 @@
 package httputils
 
 import (
+	"encoding/json"
 	"github.com/jarcoal/httpmock"
 	"github.com/privatesquare/bkst-go-utils/utils/errors"
 	"net/http"
@@ -17,8 +18,10 @@ const (
 	NotImplementedYetMsg      = "Not Implemented"
 	AuthorizationErrMsg       = "Insufficient privileges"
 	ApiHealthPath             = "/health"
+	ErrorCodesPath            = "/errors"
 	ContentTypeHeaderKey      = "Content-Type"
 	AcceptHeaderKey           = "Accept"
+	AcceptLanguageHeaderKey   = "Accept-Language"
 	AuthorizationHeaderKey    = "Authorization"
 	ApplicationJsonMIMEType   = "application/json"
 	TextPlainMIMEType         = "text/plain"
@@ -36,7 +39,8 @@ type RestErrMsg struct {
 	Error string `json:"error"`
 }
 
-// CheckHTTPErrorStatusCode checks for default errors like 401, 403 and API error status codes that are not defined.
+// CheckHTTPErrorStatusCode maps an error status code of a http response to a RestErr with the same status code.
+// Status codes that are not 4xx or 5xx are mapped to an internal server RestErr.
 func CheckHTTPErrorStatusCode(statusCode int, errMsg string) *errors.RestErr {
 	switch statusCode {
 	case http.StatusBadRequest:
@@ -45,9 +49,52 @@ func CheckHTTPErrorStatusCode(statusCode int, errMsg string) *errors.RestErr {
 		return errors.UnauthorizedError(errMsg)
 	case http.StatusForbidden:
 		return errors.ForbiddenError(errMsg)
-	default:
-		return errors.InternalServerError(errMsg)
+	case http.StatusNotFound:
+		return errors.NotFoundError(errMsg)
+	case http.StatusMethodNotAllowed:
+		return errors.MethodNotAllowedError(errMsg)
+	case http.StatusRequestTimeout:
+		return errors.RequestTimeoutError(errMsg)
+	case http.StatusConflict:
+		return errors.ConflictError(errMsg)
+	case http.StatusGone:
+		return errors.GoneError(errMsg)
+	case http.StatusPreconditionFailed:
+		return errors.PreconditionFailedError(errMsg)
+	case http.StatusUnsupportedMediaType:
+		return errors.UnsupportedMediaTypeError(errMsg)
+	case http.StatusUnprocessableEntity:
+		return errors.UnprocessableEntityError(errMsg)
+	case http.StatusTooManyRequests:
+		return errors.TooManyRequestsError(errMsg)
+	case http.StatusNotImplemented:
+		return errors.NotImplementedError(errMsg)
+	case http.StatusBadGateway:
+		return errors.BadGatewayError(errMsg)
+	case http.StatusServiceUnavailable:
+		return errors.ServiceUnavailableError(errMsg)
+	case http.StatusGatewayTimeout:
+		return errors.GatewayTimeoutError(errMsg)
 	}
+	if statusCode >= http.StatusBadRequest && statusCode < 600 && statusCode != http.StatusInternalServerError {
+		return errors.NewRestError(statusCode, errMsg)
+	}
+	return errors.InternalServerError(errMsg)
+}
+
+// CheckHTTPErrorResponse maps an error response to a RestErr with the status code of the response.
+// If the body is a json RestErr its message, error and fields are kept, otherwise the body is used as the message
+// like in CheckHTTPErrorStatusCode.
+func CheckHTTPErrorResponse(statusCode int, body []byte) *errors.RestErr {
+	restErr := new(errors.RestErr)
+	if err := json.Unmarshal(body, restErr); err != nil || restErr.Message == "" || restErr.Err == "" {
+		return CheckHTTPErrorStatusCode(statusCode, string(body))
+	}
+	if statusCode < http.StatusBadRequest || statusCode >= 600 {
+		return errors.InternalServerError(restErr.Message)
+	}
+	restErr.StatusCode = statusCode
+	return restErr
 }
 
 // NewStringToJsonResponder is a custom httpmock.Responder that takes the status code and a json string body
@@ -0,0 +1,55 @@
+package httputils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+const (
+	upstreamErrMsg = "some upstream error"
+)
+
+func TestCheckHTTPErrorStatusCode(t *testing.T) {
+	statusCodes := []int{
+		http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+		http.StatusMethodNotAllowed, http.StatusRequestTimeout, http.StatusConflict, http.StatusGone,
+		http.StatusPreconditionFailed, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity,
+		http.StatusTooManyRequests, http.StatusNotImplemented, http.StatusBadGateway, http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout, http.StatusTeapot, http.StatusHTTPVersionNotSupported,
+	}
+	for _, statusCode := range statusCodes {
+		err := CheckHTTPErrorStatusCode(statusCode, upstreamErrMsg)
+		assert.Equal(t, statusCode, err.StatusCode)
+		assert.Equal(t, http.StatusText(statusCode), err.Err)
+		assert.Equal(t, upstreamErrMsg, err.Message)
+	}
+
+	for _, statusCode := range []int{http.StatusInternalServerError, http.StatusOK, http.StatusFound} {
+		err := CheckHTTPErrorStatusCode(statusCode, upstreamErrMsg)
+		assert.Equal(t, http.StatusInternalServerError, err.StatusCode)
+		assert.Equal(t, upstreamErrMsg, err.Err)
+		assert.Equal(t, InternalServerErrMsg, err.Message)
+	}
+}
+
+func TestCheckHTTPErrorResponse(t *testing.T) {
+	t.Run("rest error body", func(t *testing.T) {
+		body := `{"message":"user not found","status":404,"error":"Not Found"}`
+		err := CheckHTTPErrorResponse(http.StatusNotFound, []byte(body))
+		assert.Equal(t, http.StatusNotFound, err.StatusCode)
+		assert.Equal(t, "user not found", err.Message)
+		assert.Equal(t, http.StatusText(http.StatusNotFound), err.Err)
+	})
+
+	t.Run("other body", func(t *testing.T) {
+		err := CheckHTTPErrorResponse(http.StatusBadGateway, []byte(upstreamErrMsg))
+		assert.Equal(t, http.StatusBadGateway, err.StatusCode)
+		assert.Equal(t, upstreamErrMsg, err.Message)
+
+		body := `{"message":"something"}`
+		err = CheckHTTPErrorResponse(http.StatusConflict, []byte(body))
+		assert.Equal(t, http.StatusConflict, err.StatusCode)
+		assert.Equal(t, body, err.Message)
+	})
+}
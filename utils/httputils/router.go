@@ -2,15 +2,22 @@ package httputils
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
 	"github.com/privatesquare/bkst-go-utils/utils/logger"
 	"net/http"
 )
 
-func NewRouter() *gin.Engine {
+// RouterOption configures a router created with NewRouter.
+type RouterOption func(r *gin.Engine)
+
+func NewRouter(opts ...RouterOption) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
 	r.Use(logger.GinZap())
 	r.Use(gin.Recovery())
+	for _, opt := range opts {
+		opt(r)
+	}
 	r.NoRoute(NoRoute)
 	r.HandleMethodNotAllowed = true
 	r.NoMethod(MethodNotAllowed)
@@ -23,10 +30,10 @@ func Health(ctx *gin.Context) {
 
 // NoRoute no route controller handles request on endpoints that are not configured
 func NoRoute(ctx *gin.Context) {
-	ctx.JSON(http.StatusNotFound, RestMsg{Message: "Path Not Found"})
+	abortWithError(ctx, errors.NotFoundError(PathNotFoundErrMsg), RestMsg{Message: PathNotFoundErrMsg})
 }
 
 // MethodNotAllowed method not allowed controller handles request on known endpoints but on methods that are not configured
 func MethodNotAllowed(ctx *gin.Context) {
-	ctx.JSON(http.StatusMethodNotAllowed, RestMsg{Message: http.StatusText(http.StatusMethodNotAllowed)})
+	abortWithError(ctx, errors.MethodNotAllowedError(MethodNotAllowedErrMsg), RestMsg{Message: MethodNotAllowedErrMsg})
 }
@@ -0,0 +1,392 @@
+package httputils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/privatesquare/bkst-go-utils/utils/logger"
+	"github.com/privatesquare/bkst-go-utils/utils/secrets"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultSessionCookieName      = "session"
+	DefaultSessionIdleTimeout     = 30 * time.Minute
+	DefaultSessionAbsoluteTimeout = 12 * time.Hour
+	SessionKey                    = "session"
+	SessionAuthMethod             = "session"
+	CSRFTokenHeaderKey            = "X-CSRF-Token"
+	SessionRequiredErrMsg         = "401 unauthorized: a valid session is required"
+	SessionExpiredErrMsg          = "401 unauthorized: the session has expired"
+	LoginFailedErrMsg             = "401 unauthorized: login failed, the username or password is incorrect"
+	CSRFTokenInvalidErrMsg        = "CSRF token is missing or not valid"
+	sessionKeysRequiredErrMsg     = "At least one session key is required"
+	sessionEncodeErrMsg           = "Unable to encode the session cookie"
+	sessionRevocationErrMsg       = "Unable to check or revoke the session"
+	csrfTokenLength               = 32
+	sessionIDLength               = 16
+)
+
+// SessionConfig represents the configuration of the session cookies.
+type SessionConfig struct {
+	// Keys encrypt and authenticate the session cookies with AES-GCM. The first key encrypts new cookies, all keys
+	// decrypt cookies, so that a new key can be added in front of the old keys to rotate them without logging out
+	// the users. Use secrets.LoadSecret to load the keys from the environment or files.
+	Keys []string
+	// CookieName is the name of the session cookie. Defaults to DefaultSessionCookieName.
+	CookieName string
+	// IdleTimeout expires a session that was not used for the duration. Defaults to DefaultSessionIdleTimeout.
+	IdleTimeout time.Duration
+	// AbsoluteTimeout expires a session after the duration since the login. Defaults to
+	// DefaultSessionAbsoluteTimeout.
+	AbsoluteTimeout time.Duration
+	// Path and Domain are the path and the domain of the session cookie. The path defaults to "/".
+	Path   string
+	Domain string
+	// Secure sends the session cookie only over https.
+	Secure bool
+	// SameSite is the SameSite attribute of the session cookie. Defaults to http.SameSiteLaxMode.
+	SameSite http.SameSite
+	// RevocationStore holds the ids of the sessions that were logged out. Without a store Logout only removes the
+	// cookie from the browser, and a copy of the cookie stays valid until the session expires.
+	RevocationStore SessionRevocationStore
+}
+
+// SessionRevocationStore stores the ids of the revoked sessions until the sessions expire.
+// Implement the interface to share the revoked sessions between multiple instances of a service.
+type SessionRevocationStore interface {
+	// Revoke revokes the session with the id until the expiry time.
+	Revoke(id string, expiresAt time.Time) error
+	// IsRevoked checks if the session with the id is revoked.
+	IsRevoked(id string) (bool, error)
+}
+
+// MemorySessionRevocationStore is an in-memory SessionRevocationStore.
+// The revoked sessions are evicted after they expire.
+type MemorySessionRevocationStore struct {
+	mu      sync.Mutex
+	revoked map[string]time.Time
+	now     func() time.Time
+}
+
+// Session is the content of an encrypted session cookie.
+type Session struct {
+	ID         string     `json:"id"`
+	Principal  *Principal `json:"principal"`
+	CSRFToken  string     `json:"csrf_token"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+}
+
+// LoginRequest represents the credentials of a login form or json body.
+type LoginRequest struct {
+	Username string `json:"username" form:"username" binding:"required"`
+	Password string `json:"password" form:"password" binding:"required"`
+}
+
+// LoginResponse represents the response of a successful login. The CSRF token must be sent in the
+// CSRFTokenHeaderKey header of the state-changing requests.
+type LoginResponse struct {
+	Username  string `json:"username"`
+	CSRFToken string `json:"csrf_token"`
+}
+
+// SessionManager issues, verifies and removes session cookies.
+type SessionManager struct {
+	cnf SessionConfig
+	now func() time.Time
+}
+
+// NewSessionManager returns a SessionManager with the configuration.
+// The method returns an error if no key is set.
+func NewSessionManager(cnf SessionConfig) (*SessionManager, error) {
+	if len(cnf.Keys) == 0 {
+		return nil, errors.New(sessionKeysRequiredErrMsg)
+	}
+	for _, key := range cnf.Keys {
+		if key == "" {
+			return nil, errors.New(sessionKeysRequiredErrMsg)
+		}
+	}
+	if cnf.CookieName == "" {
+		cnf.CookieName = DefaultSessionCookieName
+	}
+	if cnf.IdleTimeout <= 0 {
+		cnf.IdleTimeout = DefaultSessionIdleTimeout
+	}
+	if cnf.AbsoluteTimeout <= 0 {
+		cnf.AbsoluteTimeout = DefaultSessionAbsoluteTimeout
+	}
+	if cnf.Path == "" {
+		cnf.Path = "/"
+	}
+	if cnf.SameSite == 0 {
+		cnf.SameSite = http.SameSiteLaxMode
+	}
+	return &SessionManager{cnf: cnf, now: time.Now}, nil
+}
+
+// Login starts a new session for the principal and writes the session cookie and the principal to the gin context.
+// A new session id and CSRF token are issued with every session.
+func (m *SessionManager) Login(ctx *gin.Context, p *Principal) (*Session, error) {
+	id, err := newRandomToken(sessionIDLength)
+	if err != nil {
+		return nil, err
+	}
+	token, err := newRandomToken(csrfTokenLength)
+	if err != nil {
+		return nil, err
+	}
+	now := m.now()
+	s := &Session{ID: id, Principal: p, CSRFToken: token, CreatedAt: now, LastSeenAt: now}
+	if err := m.writeCookie(ctx, s); err != nil {
+		return nil, err
+	}
+	ctx.Set(SessionKey, s)
+	SetPrincipal(ctx, p)
+	return s, nil
+}
+
+// Logout removes the session cookie. The session is revoked if a RevocationStore is configured, so that a copy
+// of the cookie is rejected as well.
+func (m *SessionManager) Logout(ctx *gin.Context) {
+	s, ok := GetSession(ctx)
+	if !ok {
+		if cookie, err := ctx.Request.Cookie(m.cnf.CookieName); err == nil {
+			s, ok = m.decode(cookie.Value)
+		}
+	}
+	if ok && m.cnf.RevocationStore != nil {
+		if err := m.cnf.RevocationStore.Revoke(s.ID, s.CreatedAt.Add(m.cnf.AbsoluteTimeout)); err != nil {
+			logger.Error(sessionRevocationErrMsg, err)
+		}
+	}
+	m.removeCookie(ctx)
+}
+
+// LoginHandler returns a gin handler that authenticates the username and the password of a login form or json
+// body with the account store and starts a new session. The handler responds with a LoginResponse.
+// The handler returns an unauthorized error if the authentication fails, use BruteForceGuard with a
+// UsernameFunc that reads the login request to protect the handler.
+func (m *SessionManager) LoginHandler(store secrets.AccountStore) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		req := new(LoginRequest)
+		if err := ctx.ShouldBind(req); err != nil {
+			AbortWithRestErr(ctx, errors.BadRequestError(InvalidPayloadErrMsg))
+			return
+		}
+		ok, err := store.Authenticate(req.Username, req.Password)
+		if err != nil {
+			logger.Error(accountStoreErrMsg, err)
+		}
+		if !ok {
+			err := errors.UnauthorizedError(LoginFailedErrMsg)
+			abortWithError(ctx, err, RestErrMsg{Error: err.Message})
+			logger.Info(err.Message)
+			return
+		}
+		s, err := m.Login(ctx, &Principal{Username: req.Username, AuthMethod: SessionAuthMethod})
+		if err != nil {
+			logger.Error(sessionEncodeErrMsg, err)
+			AbortWithRestErr(ctx, errors.InternalServerError(InternalServerErrMsg))
+			return
+		}
+		logger.Info(authenticationSuccessMsg)
+		ctx.JSON(http.StatusOK, LoginResponse{Username: req.Username, CSRFToken: s.CSRFToken})
+	}
+}
+
+// LogoutHandler returns a gin handler that logs out the session, see Logout, and responds with no content.
+func (m *SessionManager) LogoutHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		m.Logout(ctx)
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
+// SessionAuth is a gin middleware for validating the session cookie of the request.
+// The method writes the Session and its Principal to the gin context and refreshes the idle timeout of the
+// cookie, which is also re-encrypted with the first key.
+// The method returns an error if the cookie is not set, is not valid or the session has expired.
+func (m *SessionManager) SessionAuth() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// the raw value is read, gin's ctx.Cookie unescapes the base64 ciphertext
+		cookie, err := ctx.Request.Cookie(m.cnf.CookieName)
+		if err != nil || cookie.Value == "" {
+			sessionAuthError(ctx, SessionRequiredErrMsg)
+			return
+		}
+		s, ok := m.decode(cookie.Value)
+		if !ok {
+			m.removeCookie(ctx)
+			sessionAuthError(ctx, SessionRequiredErrMsg)
+			return
+		}
+		now := m.now()
+		if now.Sub(s.LastSeenAt) > m.cnf.IdleTimeout || now.Sub(s.CreatedAt) > m.cnf.AbsoluteTimeout {
+			m.removeCookie(ctx)
+			sessionAuthError(ctx, SessionExpiredErrMsg)
+			return
+		}
+		s.LastSeenAt = now
+		if err := m.writeCookie(ctx, s); err != nil {
+			logger.Error(sessionEncodeErrMsg, err)
+		}
+		ctx.Set(SessionKey, s)
+		SetPrincipal(ctx, s.Principal)
+		ctx.Next()
+	}
+}
+
+// CSRF is a gin middleware that checks the CSRF token of the state-changing requests of a session.
+// The middleware must follow SessionAuth. Requests with the GET, HEAD, OPTIONS and TRACE methods are allowed,
+// other requests must send the token of the session in the CSRFTokenHeaderKey header.
+// The method returns a forbidden error if the token is missing or does not match.
+func CSRF() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		switch ctx.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			ctx.Next()
+			return
+		}
+		s, ok := GetSession(ctx)
+		token := ctx.GetHeader(CSRFTokenHeaderKey)
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.CSRFToken)) != 1 {
+			logger.Info(CSRFTokenInvalidErrMsg)
+			AbortWithRestErr(ctx, errors.ForbiddenError(CSRFTokenInvalidErrMsg))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// GetSession returns the session of the request that was set by SessionAuth or Login.
+func GetSession(ctx *gin.Context) (*Session, bool) {
+	v, ok := ctx.Get(SessionKey)
+	if !ok {
+		return nil, false
+	}
+	s, ok := v.(*Session)
+	return s, ok
+}
+
+// writeCookie encrypts the session with the first key and writes the session cookie.
+func (m *SessionManager) writeCookie(ctx *gin.Context, s *Session) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	value, err := secrets.EncryptPassword(string(data), m.cnf.Keys[0])
+	if err != nil {
+		return err
+	}
+	maxAge := m.cnf.IdleTimeout
+	if remaining := s.CreatedAt.Add(m.cnf.AbsoluteTimeout).Sub(m.now()); remaining < maxAge {
+		maxAge = remaining
+	}
+	http.SetCookie(ctx.Writer, m.cookie(value, int(maxAge.Seconds())))
+	return nil
+}
+
+// decode decrypts the session cookie with the keys in order and checks that the session is not revoked.
+// The sessions are rejected if the revocation store fails.
+func (m *SessionManager) decode(value string) (*Session, bool) {
+	for _, key := range m.cnf.Keys {
+		data, err := secrets.DecryptPassword(value, key)
+		if err != nil {
+			continue
+		}
+		s := new(Session)
+		if err := json.Unmarshal([]byte(data), s); err != nil || s.Principal == nil {
+			return nil, false
+		}
+		if m.cnf.RevocationStore != nil {
+			if s.ID == "" {
+				return nil, false
+			}
+			revoked, err := m.cnf.RevocationStore.IsRevoked(s.ID)
+			if err != nil {
+				logger.Error(sessionRevocationErrMsg, err)
+			}
+			if err != nil || revoked {
+				return nil, false
+			}
+		}
+		return s, true
+	}
+	return nil, false
+}
+
+// removeCookie removes the session cookie.
+func (m *SessionManager) removeCookie(ctx *gin.Context) {
+	http.SetCookie(ctx.Writer, m.cookie("", -1))
+}
+
+// cookie returns the session cookie with the value.
+func (m *SessionManager) cookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     m.cnf.CookieName,
+		Value:    value,
+		Path:     m.cnf.Path,
+		Domain:   m.cnf.Domain,
+		MaxAge:   maxAge,
+		Secure:   m.cnf.Secure,
+		HttpOnly: true,
+		SameSite: m.cnf.SameSite,
+	}
+}
+
+// newRandomToken returns a new random token of the length in bytes, for example a session id or a CSRF token.
+func newRandomToken(length int) (string, error) {
+	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// NewMemorySessionRevocationStore returns a new in-memory SessionRevocationStore.
+func NewMemorySessionRevocationStore() *MemorySessionRevocationStore {
+	return &MemorySessionRevocationStore{revoked: make(map[string]time.Time), now: time.Now}
+}
+
+// Revoke implements the SessionRevocationStore interface. The expired sessions are evicted.
+func (s *MemorySessionRevocationStore) Revoke(id string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for key, exp := range s.revoked {
+		if !now.Before(exp) {
+			delete(s.revoked, key)
+		}
+	}
+	s.revoked[id] = expiresAt
+	return nil
+}
+
+// IsRevoked implements the SessionRevocationStore interface.
+func (s *MemorySessionRevocationStore) IsRevoked(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.revoked[id]
+	return ok, nil
+}
+
+// Len returns the number of revoked sessions in the store.
+func (s *MemorySessionRevocationStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.revoked)
+}
+
+// sessionAuthError writes an unauthorized error to the gin context if the session authentication fails.
+func sessionAuthError(ctx *gin.Context, msg string) {
+	err := errors.UnauthorizedError(msg)
+	abortWithError(ctx, err, RestErrMsg{Error: err.Message})
+	logger.Info(err.Message)
+}
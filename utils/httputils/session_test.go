@@ -0,0 +1,234 @@
+package httputils
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/secrets"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupSessionRouter returns a router with the login, logout and session protected routes of the session manager.
+func setupSessionRouter(m *SessionManager) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.POST("/login", m.LoginHandler(secrets.PlainAccountStore{"admin": "admin"}))
+	router.POST("/logout", m.LogoutHandler())
+	protected := router.Group("/", m.SessionAuth(), CSRF())
+	protected.GET("/me", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, ctx.GetString(AuthUserKey))
+	})
+	protected.POST("/items", func(ctx *gin.Context) {
+		ctx.Status(http.StatusCreated)
+	})
+	return router
+}
+
+// sessionCookie returns the session cookie of the response.
+func sessionCookie(w *httptest.ResponseRecorder) *http.Cookie {
+	for _, c := range w.Result().Cookies() {
+		if c.Name == DefaultSessionCookieName {
+			return c
+		}
+	}
+	return nil
+}
+
+// sessionLogin logs in with the credentials and returns the response.
+func sessionLogin(router *gin.Engine, username, password string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	form := url.Values{"username": {username}, "password": {password}}
+	req, _ := http.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	req.Header.Set(ContentTypeHeaderKey, XwwwFromUrlencodeMIMEType)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// sessionRequest sends a request with the session cookie and the CSRF token.
+func sessionRequest(router *gin.Engine, method, path string, cookie *http.Cookie, token string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	if token != "" {
+		req.Header.Set(CSRFTokenHeaderKey, token)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestNewSessionManager(t *testing.T) {
+	_, err := NewSessionManager(SessionConfig{})
+	assert.EqualError(t, err, sessionKeysRequiredErrMsg)
+
+	_, err = NewSessionManager(SessionConfig{Keys: []string{""}})
+	assert.EqualError(t, err, sessionKeysRequiredErrMsg)
+
+	m, err := NewSessionManager(SessionConfig{Keys: []string{"key"}})
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultSessionCookieName, m.cnf.CookieName)
+	assert.Equal(t, DefaultSessionIdleTimeout, m.cnf.IdleTimeout)
+	assert.Equal(t, DefaultSessionAbsoluteTimeout, m.cnf.AbsoluteTimeout)
+	assert.Equal(t, "/", m.cnf.Path)
+	assert.Equal(t, http.SameSiteLaxMode, m.cnf.SameSite)
+}
+
+func TestSessionManager_Login(t *testing.T) {
+	m, _ := NewSessionManager(SessionConfig{Keys: []string{"key"}, Secure: true})
+	router := setupSessionRouter(m)
+
+	w := sessionLogin(router, "admin", "wrong")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `{"error":"`+LoginFailedErrMsg+`"}`, w.Body.String())
+	assert.Nil(t, sessionCookie(w))
+
+	w = sessionLogin(router, "admin", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = sessionLogin(router, "admin", "admin")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"username":"admin"`)
+	cookie := sessionCookie(w)
+	if assert.NotNil(t, cookie) {
+		assert.True(t, cookie.HttpOnly)
+		assert.True(t, cookie.Secure)
+		assert.Equal(t, int(DefaultSessionIdleTimeout.Seconds()), cookie.MaxAge)
+		assert.NotContains(t, cookie.Value, "admin")
+	}
+
+	w = sessionRequest(router, http.MethodGet, "/me", cookie, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "admin", w.Body.String())
+	assert.NotNil(t, sessionCookie(w))
+
+	w = sessionRequest(router, http.MethodGet, "/me", nil, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `{"error":"`+SessionRequiredErrMsg+`"}`, w.Body.String())
+
+	tampered := *cookie
+	if cookie.Value[0] == 'A' {
+		tampered.Value = "B" + cookie.Value[1:]
+	} else {
+		tampered.Value = "A" + cookie.Value[1:]
+	}
+	w = sessionRequest(router, http.MethodGet, "/me", &tampered, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSessionManager_Logout(t *testing.T) {
+	m, _ := NewSessionManager(SessionConfig{Keys: []string{"key"}})
+	router := setupSessionRouter(m)
+
+	w := sessionRequest(router, http.MethodPost, "/logout", nil, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	cookie := sessionCookie(w)
+	if assert.NotNil(t, cookie) {
+		assert.Empty(t, cookie.Value)
+		assert.True(t, cookie.MaxAge < 0)
+	}
+}
+
+func TestSessionManager_Revocation(t *testing.T) {
+	store := NewMemorySessionRevocationStore()
+	m, _ := NewSessionManager(SessionConfig{Keys: []string{"key"}, RevocationStore: store})
+	router := setupSessionRouter(m)
+
+	cookie := sessionCookie(sessionLogin(router, "admin", "admin"))
+	other := sessionCookie(sessionLogin(router, "admin", "admin"))
+	assert.Equal(t, http.StatusOK, sessionRequest(router, http.MethodGet, "/me", cookie, "").Code)
+
+	w := sessionRequest(router, http.MethodPost, "/logout", cookie, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, 1, store.Len())
+
+	// a copy of the logged out cookie is rejected, other sessions of the user are not affected
+	w = sessionRequest(router, http.MethodGet, "/me", cookie, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `{"error":"`+SessionRequiredErrMsg+`"}`, w.Body.String())
+	assert.Equal(t, http.StatusOK, sessionRequest(router, http.MethodGet, "/me", other, "").Code)
+
+	// the revoked sessions are evicted after they expire
+	store.now = func() time.Time { return time.Now().Add(DefaultSessionAbsoluteTimeout + time.Minute) }
+	assert.NoError(t, store.Revoke("id", time.Now().Add(2*DefaultSessionAbsoluteTimeout)))
+	assert.Equal(t, 1, store.Len())
+}
+
+func TestSessionManager_Timeouts(t *testing.T) {
+	m, _ := NewSessionManager(SessionConfig{Keys: []string{"key"}, IdleTimeout: time.Minute, AbsoluteTimeout: time.Hour})
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	router := setupSessionRouter(m)
+	cookie := sessionCookie(sessionLogin(router, "admin", "admin"))
+
+	// the idle timeout is refreshed by every request
+	for i := 0; i < 3; i++ {
+		now = now.Add(50 * time.Second)
+		w := sessionRequest(router, http.MethodGet, "/me", cookie, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		cookie = sessionCookie(w)
+	}
+
+	now = now.Add(2 * time.Minute)
+	w := sessionRequest(router, http.MethodGet, "/me", cookie, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `{"error":"`+SessionExpiredErrMsg+`"}`, w.Body.String())
+
+	// the absolute timeout is not refreshed
+	cookie = sessionCookie(sessionLogin(router, "admin", "admin"))
+	for i := 0; i < 70; i++ {
+		now = now.Add(59 * time.Second)
+		w = sessionRequest(router, http.MethodGet, "/me", cookie, "")
+		if w.Code != http.StatusOK {
+			break
+		}
+		cookie = sessionCookie(w)
+	}
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `{"error":"`+SessionExpiredErrMsg+`"}`, w.Body.String())
+}
+
+func TestSessionManager_KeyRotation(t *testing.T) {
+	old, _ := NewSessionManager(SessionConfig{Keys: []string{"old"}})
+	cookie := sessionCookie(sessionLogin(setupSessionRouter(old), "admin", "admin"))
+
+	rotated, _ := NewSessionManager(SessionConfig{Keys: []string{"new", "old"}})
+	w := sessionRequest(setupSessionRouter(rotated), http.MethodGet, "/me", cookie, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// the refreshed cookie is encrypted with the new key
+	current, _ := NewSessionManager(SessionConfig{Keys: []string{"new"}})
+	w = sessionRequest(setupSessionRouter(current), http.MethodGet, "/me", sessionCookie(w), "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = sessionRequest(setupSessionRouter(current), http.MethodGet, "/me", cookie, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestCSRF(t *testing.T) {
+	m, _ := NewSessionManager(SessionConfig{Keys: []string{"key"}})
+	router := setupSessionRouter(m)
+	w := sessionLogin(router, "admin", "admin")
+	cookie := sessionCookie(w)
+	resp := new(LoginResponse)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), resp))
+	token := resp.CSRFToken
+
+	w = sessionRequest(router, http.MethodPost, "/items", cookie, token)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = sessionRequest(router, http.MethodPost, "/items", cookie, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = sessionRequest(router, http.MethodPost, "/items", cookie, "invalid")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	other := sessionCookie(sessionLogin(router, "admin", "admin"))
+	w = sessionRequest(router, http.MethodPost, "/items", other, token)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,153 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"regexp"
+)
+
+const (
+	RequestIDHeaderKey   = "X-Request-Id"
+	TraceParentHeaderKey = "traceparent"
+	RequestIDKey         = "request_id"
+	RequestIDFieldKey    = "request_id"
+	TraceIDFieldKey      = "trace_id"
+	UserFieldKey         = "user"
+	requestIDLength      = 16
+	invalidTraceID       = "00000000000000000000000000000000"
+)
+
+var (
+	// validRequestID matches the request ids of the RequestIDHeaderKey header that are accepted by GinZap.
+	validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+	// traceParent matches a W3C trace context traceparent header and captures the trace id.
+	traceParent = regexp.MustCompile(`^[0-9a-f]{2}-([0-9a-f]{32})-[0-9a-f]{16}-[0-9a-f]{2}$`)
+)
+
+// loggerContextKey is the key of the Logger in a context.
+type loggerContextKey struct{}
+
+// requestIDContextKey is the key of the request id in a context.
+type requestIDContextKey struct{}
+
+// userContextKey is the key of the user in a context.
+type userContextKey struct{}
+
+// NewContext returns a copy of the context that carries the logger.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, l)
+}
+
+// ContextWithFields returns a copy of the context whose logger adds the fields to every log, for example the
+// user of a request.
+func ContextWithFields(ctx context.Context, fields ...zapcore.Field) context.Context {
+	return NewContext(ctx, contextLogger(ctx).With(fields...))
+}
+
+// ContextWithUser returns a copy of the context whose logger adds the UserFieldKey field to every log.
+// The user of a context that already has a user is replaced instead of adding a second field.
+func ContextWithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userContextKey{}, user)
+}
+
+// FromContext returns the logger of the context, or the global logger if the context has no logger.
+// For a *gin.Context the logger of the request context is returned.
+// The user of ContextWithUser is added to the logger as the UserFieldKey field.
+func FromContext(ctx context.Context) *Logger {
+	l := contextLogger(ctx)
+	if ctx = requestCtx(ctx); ctx != nil {
+		if user, ok := ctx.Value(userContextKey{}).(string); ok {
+			return l.With(zap.String(UserFieldKey, user))
+		}
+	}
+	return l
+}
+
+// contextLogger returns the logger of the context without the user, or the global logger if the context has no
+// logger.
+func contextLogger(ctx context.Context) *Logger {
+	if ctx = requestCtx(ctx); ctx != nil {
+		if l, ok := ctx.Value(loggerContextKey{}).(*Logger); ok {
+			return l
+		}
+	}
+	return L()
+}
+
+// requestCtx returns the request context of a *gin.Context, or nil if the gin context has no request.
+// Other contexts are returned as they are.
+func requestCtx(ctx context.Context) context.Context {
+	if c, ok := ctx.(*gin.Context); ok {
+		if c.Request == nil {
+			return nil
+		}
+		return c.Request.Context()
+	}
+	return ctx
+}
+
+// RequestID returns the request id that GinZap added to the context, or an empty string.
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if c, ok := ctx.(*gin.Context); ok {
+		return c.GetString(RequestIDKey)
+	}
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// InfoCtx logs the message on the info level with the fields of the logger of the context.
+func InfoCtx(ctx context.Context, msg string, tags ...zapcore.Field) {
+	FromContext(ctx).skip(1).Info(msg, tags...)
+}
+
+// WarnCtx logs the message on the warn level with the fields of the logger of the context.
+func WarnCtx(ctx context.Context, msg string, tags ...zapcore.Field) {
+	FromContext(ctx).skip(1).Warn(msg, tags...)
+}
+
+// ErrorCtx logs the message and the error on the error level with the fields of the logger of the context,
+// see Error.
+func ErrorCtx(ctx context.Context, msg string, err error, tags ...zapcore.Field) {
+	FromContext(ctx).skip(1).Error(msg, errorFields(err, tags)...)
+}
+
+// DebugCtx logs the message on the debug level with the fields of the logger of the context.
+func DebugCtx(ctx context.Context, msg string, tags ...zapcore.Field) {
+	FromContext(ctx).skip(1).Debug(msg, tags...)
+}
+
+// requestContext adds the request id and the trace id of the request to the gin context and to the logger of
+// the request context. The request id is taken from the RequestIDHeaderKey header if it is valid, otherwise a
+// new id is generated, and is returned in the response header. The trace id is taken from the W3C
+// traceparent header.
+func requestContext(c *gin.Context) {
+	requestID := c.GetHeader(RequestIDHeaderKey)
+	if !validRequestID.MatchString(requestID) {
+		requestID = newRequestID()
+	}
+	c.Set(RequestIDKey, requestID)
+	c.Header(RequestIDHeaderKey, requestID)
+
+	fields := []zapcore.Field{zap.String(RequestIDFieldKey, requestID)}
+	if m := traceParent.FindStringSubmatch(c.GetHeader(TraceParentHeaderKey)); m != nil && m[1] != invalidTraceID {
+		fields = append(fields, zap.String(TraceIDFieldKey, m[1]))
+	}
+	ctx := context.WithValue(c.Request.Context(), requestIDContextKey{}, requestID)
+	c.Request = c.Request.WithContext(ContextWithFields(ctx, fields...))
+}
+
+// newRequestID returns a new random request id.
+func newRequestID() string {
+	b := make([]byte, requestIDLength)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFromContext(t *testing.T) {
+	configureMockLogger(DefaultLogLevel)
+	assert.Same(t, L(), FromContext(context.Background()))
+
+	l := Named("component")
+	ctx := NewContext(context.Background(), l)
+	assert.Same(t, l, FromContext(ctx))
+
+	ctx = ContextWithFields(ctx, zap.String("tenant", "acme"))
+	InfoCtx(ctx, "some info message")
+	WarnCtx(ctx, "some warning message")
+	ErrorCtx(ctx, "some error message", nil)
+	DebugCtx(ctx, "some debug message")
+
+	output := Sink.String()
+	t.Logf("output = %s", output)
+
+	assert.Equal(t, 3, strings.Count(output, "\"logger\":\"component\""))
+	assert.Equal(t, 3, strings.Count(output, "\"tenant\":\"acme\""))
+	assert.Equal(t, 3, strings.Count(output, "\"caller\":\"logger/context_test.go"))
+	assert.False(t, strings.Contains(output, "some debug message"))
+
+	gc, _ := gin.CreateTestContext(httptest.NewRecorder())
+	assert.Same(t, L(), FromContext(gc))
+}
+
+func TestContextWithUser(t *testing.T) {
+	configureMockLogger(DefaultLogLevel)
+	ctx := ContextWithFields(context.Background(), zap.String("tenant", "acme"))
+	ctx = ContextWithUser(ctx, "admin")
+	ctx = ContextWithUser(ctx, "other")
+	InfoCtx(ctx, "some info message")
+
+	// fields that were added after the user are kept
+	ctx = ContextWithUser(ContextWithFields(ctx, zap.String("region", "eu")), "admin")
+	InfoCtx(ctx, "some other message")
+
+	// fields that are added after the user do not repeat the user
+	InfoCtx(ContextWithFields(ctx, zap.String("zone", "a")), "some third message")
+
+	output := Sink.String()
+	t.Logf("output = %s", output)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if assert.Len(t, lines, 3) {
+		assert.Equal(t, 1, strings.Count(lines[0], "\"user\""))
+		assert.True(t, strings.Contains(lines[0], "\"user\":\"other\""))
+		assert.True(t, strings.Contains(lines[0], "\"tenant\":\"acme\""))
+		assert.Equal(t, 1, strings.Count(lines[1], "\"user\""))
+		assert.True(t, strings.Contains(lines[1], "\"user\":\"admin\""))
+		assert.True(t, strings.Contains(lines[1], "\"tenant\":\"acme\""))
+		assert.True(t, strings.Contains(lines[1], "\"region\":\"eu\""))
+		assert.Equal(t, 1, strings.Count(lines[2], "\"user\""))
+		assert.True(t, strings.Contains(lines[2], "\"zone\":\"a\""))
+	}
+}
+
+func TestRequestID_NilContext(t *testing.T) {
+	assert.Empty(t, RequestID(nil))
+	assert.Empty(t, RequestID(context.Background()))
+}
+
+func TestGinZap_RequestContext(t *testing.T) {
+	configureMockLogger(DefaultLogLevel)
+	r := newRouter()
+
+	var requestID string
+	r.GET("/test", func(ctx *gin.Context) {
+		requestID = RequestID(ctx)
+		assert.Equal(t, requestID, RequestID(ctx.Request.Context()))
+		InfoCtx(ctx, "some handler message")
+		ctx.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(TraceParentHeaderKey, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(w, req)
+
+	output := Sink.String()
+	t.Logf("output = %s", output)
+
+	assert.Len(t, requestID, 2*requestIDLength)
+	assert.Equal(t, requestID, w.Header().Get(RequestIDHeaderKey))
+	assert.Equal(t, 2, strings.Count(output, "\"request_id\":\""+requestID+"\""))
+	assert.Equal(t, 2, strings.Count(output, "\"trace_id\":\"4bf92f3577b34da6a3ce929d0e0e4736\""))
+
+	tests := []struct {
+		name      string
+		requestID string
+		valid     bool
+	}{
+		{"valid", "req-123_abc.1", true},
+		{"invalid characters", "req 123\n", false},
+		{"too long", strings.Repeat("a", 129), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Sink.Reset()
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set(RequestIDHeaderKey, tt.requestID)
+			req.Header.Set(TraceParentHeaderKey, "00-00000000000000000000000000000000-00f067aa0ba902b7-01")
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.valid, requestID == tt.requestID)
+			assert.Equal(t, requestID, w.Header().Get(RequestIDHeaderKey))
+			assert.False(t, strings.Contains(Sink.String(), TraceIDFieldKey))
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package logger
+
+import (
+	"fmt"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"sync/atomic"
+)
+
+const (
+	nilLoggerErrMsg   = "logger must not be nil"
+	buildLoggerErrMsg = "unable to initialize the logger: %v"
+)
+
+var (
+	// global holds the *Logger that is used by the package level functions.
+	global atomic.Value
+)
+
+// Logger is a structured logger built on zap. A Logger can be passed around, scoped to a component with Named
+// and enriched with fields with With. Loggers are safe for concurrent use.
+type Logger struct {
+	zap *zap.Logger
+	// level is the atomic level of the zap core, nil if the logger was not built from a configuration.
+	level *zap.AtomicLevel
+}
+
+// Option configures a Logger built by New.
+type Option func(cfg *zap.Config)
+
+// WithLevel sets the log level, see ParseLevel. Invalid levels fall back to "INFO".
+func WithLevel(level string) Option {
+	return func(cfg *zap.Config) {
+		cfg.Level = zap.NewAtomicLevelAt(zapLevel(level))
+	}
+}
+
+// WithOutputPaths sets the output paths of the logs, for example "stdout" or a file path.
+func WithOutputPaths(paths ...string) Option {
+	return func(cfg *zap.Config) {
+		cfg.OutputPaths = paths
+	}
+}
+
+// WithEncoding sets the encoding of the logs, "json" or "console".
+func WithEncoding(encoding string) Option {
+	return func(cfg *zap.Config) {
+		cfg.Encoding = encoding
+	}
+}
+
+// WithInitialFields adds the fields to every log of the logger, for example the service name.
+func WithInitialFields(fields map[string]interface{}) Option {
+	return func(cfg *zap.Config) {
+		if cfg.InitialFields == nil {
+			cfg.InitialFields = make(map[string]interface{}, len(fields))
+		}
+		for k, v := range fields {
+			cfg.InitialFields[k] = v
+		}
+	}
+}
+
+// New returns a Logger with the default configuration of GetLoggerConfig modified by the options.
+func New(opts ...Option) (*Logger, error) {
+	cfg := GetLoggerConfig(DefaultLogLevel)
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return NewFromConfig(cfg)
+}
+
+// NewFromConfig returns a Logger built from the zap configuration.
+func NewFromConfig(cfg zap.Config) (*Logger, error) {
+	z, err := cfg.Build()
+	if err != nil {
+		return nil, errors.Newf(buildLoggerErrMsg, err)
+	}
+	l := &Logger{zap: z}
+	if cfg.Level != (zap.AtomicLevel{}) {
+		level := cfg.Level
+		l.level = &level
+	}
+	return l, nil
+}
+
+// NewFromZap returns a Logger that writes to the zap logger. The level of the logger can't be changed with
+// SetLevel.
+func NewFromZap(z *zap.Logger) *Logger {
+	return &Logger{zap: z}
+}
+
+// L returns the global logger that is used by the package level functions.
+func L() *Logger {
+	return global.Load().(*Logger)
+}
+
+// ReplaceGlobal replaces the global logger. It is safe to replace the global logger while other goroutines log.
+// A pending revert of the log level of the LogLevelHandler is cancelled, so that it doesn't change the level of
+// the new logger. The method returns an error if the logger is nil.
+func ReplaceGlobal(l *Logger) error {
+	if l == nil || l.zap == nil {
+		return errors.New(nilLoggerErrMsg)
+	}
+	revert.Lock()
+	defer revert.Unlock()
+	global.Store(l)
+	cancelRevert()
+	return nil
+}
+
+// Named returns a component logger of the global logger, see Logger.Named.
+func Named(name string) *Logger {
+	return L().Named(name)
+}
+
+// With returns a child logger of the global logger with the fields, see Logger.With.
+func With(fields ...zapcore.Field) *Logger {
+	return L().With(fields...)
+}
+
+// Named returns a sub-logger whose name is appended to the name of the logger with a ".", for example
+// "httputils.client". The name is logged as the "logger" field.
+func (l *Logger) Named(name string) *Logger {
+	return &Logger{zap: l.zap.Named(name), level: l.level}
+}
+
+// With returns a child logger that adds the fields to every log.
+func (l *Logger) With(fields ...zapcore.Field) *Logger {
+	return &Logger{zap: l.zap.With(fields...), level: l.level}
+}
+
+// Zap returns the underlying zap logger.
+func (l *Logger) Zap() *zap.Logger {
+	return l.zap
+}
+
+// Sync flushes the buffered logs.
+func (l *Logger) Sync() error {
+	return l.zap.Sync()
+}
+
+// Debug logs the message on the debug level.
+func (l *Logger) Debug(msg string, tags ...zapcore.Field) {
+	l.skip(1).Debug(msg, tags...)
+}
+
+// Debugf formats and logs the message on the debug level.
+func (l *Logger) Debugf(format string, a ...interface{}) {
+	l.sugar(1).Debugf(format, a...)
+}
+
+// Debugw logs the message and the key/value pairs on the debug level.
+func (l *Logger) Debugw(msg string, keysAndValues ...interface{}) {
+	l.sugar(1).Debugw(msg, keysAndValues...)
+}
+
+// Info logs the message on the info level.
+func (l *Logger) Info(msg string, tags ...zapcore.Field) {
+	l.skip(1).Info(msg, tags...)
+}
+
+// Infof formats and logs the message on the info level.
+func (l *Logger) Infof(format string, a ...interface{}) {
+	l.sugar(1).Infof(format, a...)
+}
+
+// Infow logs the message and the key/value pairs on the info level.
+func (l *Logger) Infow(msg string, keysAndValues ...interface{}) {
+	l.sugar(1).Infow(msg, keysAndValues...)
+}
+
+// Warn logs the message on the warn level.
+func (l *Logger) Warn(msg string, tags ...zapcore.Field) {
+	l.skip(1).Warn(msg, tags...)
+}
+
+// Warnf formats and logs the message on the warn level.
+func (l *Logger) Warnf(format string, a ...interface{}) {
+	l.sugar(1).Warnf(format, a...)
+}
+
+// Warnw logs the message and the key/value pairs on the warn level.
+func (l *Logger) Warnw(msg string, keysAndValues ...interface{}) {
+	l.sugar(1).Warnw(msg, keysAndValues...)
+}
+
+// Error logs the message and the error on the error level, see the package level Error.
+func (l *Logger) Error(msg string, err error, tags ...zapcore.Field) {
+	l.skip(1).Error(msg, errorFields(err, tags)...)
+}
+
+// Errorf formats and logs the message on the error level, see the package level Errorf.
+func (l *Logger) Errorf(format string, a ...interface{}) {
+	l.skip(1).Error(fmt.Sprintf(format, a...), errorFields(firstError(a), nil)...)
+}
+
+// Errorw logs the message and the key/value pairs on the error level.
+func (l *Logger) Errorw(msg string, keysAndValues ...interface{}) {
+	l.sugar(1).Errorw(msg, keysAndValues...)
+}
+
+// Panic logs the message on the panic level and panics.
+func (l *Logger) Panic(msg string, tags ...zapcore.Field) {
+	l.skip(1).Panic(msg, tags...)
+}
+
+// Panicf formats and logs the message on the panic level and panics.
+func (l *Logger) Panicf(format string, a ...interface{}) {
+	l.sugar(1).Panicf(format, a...)
+}
+
+// Panicw logs the message and the key/value pairs on the panic level and panics.
+func (l *Logger) Panicw(msg string, keysAndValues ...interface{}) {
+	l.sugar(1).Panicw(msg, keysAndValues...)
+}
+
+// Fatal logs the message on the fatal level and exits the process with status 1.
+func (l *Logger) Fatal(msg string, tags ...zapcore.Field) {
+	l.skip(1).Fatal(msg, tags...)
+}
+
+// Fatalf formats and logs the message on the fatal level and exits the process with status 1.
+func (l *Logger) Fatalf(format string, a ...interface{}) {
+	l.sugar(1).Fatalf(format, a...)
+}
+
+// Fatalw logs the message and the key/value pairs on the fatal level and exits the process with status 1.
+func (l *Logger) Fatalw(msg string, keysAndValues ...interface{}) {
+	l.sugar(1).Fatalw(msg, keysAndValues...)
+}
+
+// skip returns the zap logger that reports the caller the number of frames above the logging method.
+func (l *Logger) skip(n int) *zap.Logger {
+	return l.zap.WithOptions(zap.AddCallerSkip(n))
+}
+
+// sugar returns the sugared zap logger that reports the caller the number of frames above the logging method.
+func (l *Logger) sugar(n int) *zap.SugaredLogger {
+	return l.skip(n).Sugar()
+}
+
+// firstError returns the first error of the arguments, or nil.
+func firstError(a []interface{}) error {
+	for _, v := range a {
+		if err, ok := v.(error); ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// errorFields returns the tags with the error, its stack trace and its context.
+func errorFields(err error, tags []zapcore.Field) []zapcore.Field {
+	if err == nil {
+		return tags
+	}
+	tags = append(tags, zap.NamedError("error", err))
+	if stack := errors.StackTrace(err); stack != "" {
+		tags = append(tags, zap.String(stackTraceFieldKey, stack))
+	}
+	if ctx := errors.Context(err); len(ctx) > 0 {
+		tags = append(tags, zap.Any(contextFieldKey, ctx))
+	}
+	return tags
+}
+
+// zapLevel returns the zap level of the log level, or the info level if the log level is not valid.
+func zapLevel(logLevel string) zapcore.Level {
+	if l, err := ParseLevel(logLevel); err == nil {
+		return l
+	}
+	return zap.InfoLevel
+}
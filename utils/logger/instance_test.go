@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	configureMockLogger(DefaultLogLevel)
+	l, err := New(
+		WithLevel(debugLogLevel),
+		WithOutputPaths("memory://"),
+		WithInitialFields(map[string]interface{}{"service": "billing"}),
+	)
+	assert.NoError(t, err)
+
+	l.Debug("some debug message")
+	output := Sink.String()
+	t.Logf("output = %s", output)
+
+	assert.True(t, strings.Contains(output, "\"level\":\"debug\""))
+	assert.True(t, strings.Contains(output, "\"service\":\"billing\""))
+	assert.True(t, strings.Contains(output, "\"caller\":\"logger/instance_test.go"))
+
+	_, err = New(WithEncoding("invalid"))
+	assert.Error(t, err)
+}
+
+func TestLogger_NamedWith(t *testing.T) {
+	configureMockLogger(DefaultLogLevel)
+	l := Named("httputils").Named("client").With(zap.String("base_url", "https://test.com"))
+
+	l.Infof("some %s message", "info")
+	l.Error("some error message", nil)
+	l.Warnw("some warning message", "attempt", 2)
+	output := Sink.String()
+	t.Logf("output = %s", output)
+
+	assert.Equal(t, 3, strings.Count(output, "\"logger\":\"httputils.client\""))
+	assert.Equal(t, 3, strings.Count(output, "\"base_url\":\"https://test.com\""))
+	assert.Equal(t, 3, strings.Count(output, "\"caller\":\"logger/instance_test.go"))
+	assert.True(t, strings.Contains(output, "\"attempt\":2"))
+	assert.True(t, strings.Contains(output, "some info message"))
+
+	Sink.Reset()
+	Info("some global message")
+	assert.False(t, strings.Contains(Sink.String(), "httputils.client"))
+}
+
+func TestReplaceGlobal(t *testing.T) {
+	configureMockLogger(DefaultLogLevel)
+	current := L()
+
+	assert.EqualError(t, ReplaceGlobal(nil), nilLoggerErrMsg)
+	assert.Same(t, current, L())
+
+	cfg := GetLoggerConfig(DefaultLogLevel)
+	cfg.Encoding = "invalid"
+	assert.Error(t, SetLoggerConfig(cfg))
+	assert.Same(t, current, L())
+
+	// the global logger can be replaced while other goroutines log
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				Info("some concurrent message")
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, ReplaceGlobal(NewFromZap(zap.NewNop())))
+	}
+	wg.Wait()
+	assert.NoError(t, ReplaceGlobal(current))
+}
@@ -0,0 +1,198 @@
+package logger
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	LogLevelPath             = "/loglevel"
+	invalidLogLevelErrMsg    = "Invalid log level : %s"
+	levelNotChangeableErrMsg = "The log level of the logger can't be changed"
+	invalidLogLevelReqErrMsg = "A valid log level and an optional positive revert_after duration are required"
+	logLevelMethodErrMsg     = "Method %s is not allowed, use GET or PUT"
+	logLevelChangedMsg       = "The log level was changed"
+	logLevelRevertedMsg      = "The log level was reverted"
+)
+
+var (
+	logLevels = map[string]zapcore.Level{
+		debugLogLevel:   zap.DebugLevel,
+		DefaultLogLevel: zap.InfoLevel,
+		"WARN":          zap.WarnLevel,
+		"ERROR":         zap.ErrorLevel,
+	}
+	// revert holds the pending automatic revert of the log level of the LogLevelHandler. The revert is cancelled
+	// if the global logger is replaced.
+	revert struct {
+		sync.Mutex
+		timer  *time.Timer
+		logger *Logger
+		level  string
+		at     time.Time
+	}
+)
+
+// LogLevelRequest represents the request of the LogLevelHandler. RevertAfter is a duration, for example "15m",
+// after which the previous log level is restored.
+type LogLevelRequest struct {
+	Level       string `json:"level"`
+	RevertAfter string `json:"revert_after,omitempty"`
+}
+
+// LogLevelResponse represents the response of the LogLevelHandler. RevertAt is set if a revert is pending.
+type LogLevelResponse struct {
+	Level    string     `json:"level"`
+	RevertAt *time.Time `json:"revert_at,omitempty"`
+}
+
+// ParseLevel returns the zap level of a log level, "DEBUG", "INFO", "WARN" or "ERROR". The log level is not case
+// sensitive.
+func ParseLevel(logLevel string) (zapcore.Level, error) {
+	l, ok := logLevels[strings.ToUpper(logLevel)]
+	if !ok {
+		return zap.InfoLevel, errors.Newf(invalidLogLevelErrMsg, logLevel)
+	}
+	return l, nil
+}
+
+// SetLevel changes the log level of the logger and of all the loggers derived from it with Named and With.
+// The method returns an error if the log level is not valid or the logger was built with NewFromZap.
+func (l *Logger) SetLevel(logLevel string) error {
+	level, err := ParseLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	if l.level == nil {
+		return errors.New(levelNotChangeableErrMsg)
+	}
+	l.level.SetLevel(level)
+	return nil
+}
+
+// Level returns the log level of the logger, for example "INFO".
+func (l *Logger) Level() string {
+	if l.level != nil {
+		return strings.ToUpper(l.level.Level().String())
+	}
+	for level := zapcore.DebugLevel; level < zapcore.FatalLevel; level++ {
+		if l.zap.Core().Enabled(level) {
+			return strings.ToUpper(level.String())
+		}
+	}
+	return strings.ToUpper(zapcore.FatalLevel.String())
+}
+
+// SetLevel changes the log level of the global logger at runtime, see Logger.SetLevel.
+func SetLevel(logLevel string) error {
+	return L().SetLevel(logLevel)
+}
+
+// GetLevel returns the log level of the global logger.
+func GetLevel() string {
+	return L().Level()
+}
+
+// LogLevelHandler returns a gin handler that reads the log level of the global logger on GET and changes it on
+// PUT with a LogLevelRequest. If the request has a revert_after duration the previous log level is restored
+// after the duration, for example to turn on DEBUG in production for a limited time. A new change cancels the
+// pending revert but keeps its log level, and replacing the global logger cancels it. Register the handler for
+// both methods, for example on LogLevelPath, behind an authorization middleware. Other methods are rejected with
+// a 405 error.
+func LogLevelHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		switch ctx.Request.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			req := new(LogLevelRequest)
+			var revertAfter time.Duration
+			err := ctx.ShouldBindJSON(req)
+			if err == nil && req.RevertAfter != "" {
+				if revertAfter, err = time.ParseDuration(req.RevertAfter); err == nil && revertAfter <= 0 {
+					err = errors.New(invalidLogLevelReqErrMsg)
+				}
+			}
+			if err == nil {
+				_, err = ParseLevel(req.Level)
+			}
+			if err != nil {
+				restErr := errors.BadRequestError(invalidLogLevelReqErrMsg)
+				ctx.AbortWithStatusJSON(restErr.StatusCode, restErr)
+				return
+			}
+			if err := changeLevel(strings.ToUpper(req.Level), revertAfter); err != nil {
+				restErr := errors.ConflictError(err.Error())
+				ctx.AbortWithStatusJSON(restErr.StatusCode, restErr)
+				return
+			}
+		default:
+			ctx.Header("Allow", http.MethodGet+", "+http.MethodPut)
+			restErr := errors.MethodNotAllowedErrorf(logLevelMethodErrMsg, ctx.Request.Method)
+			ctx.AbortWithStatusJSON(restErr.StatusCode, restErr)
+			return
+		}
+		revert.Lock()
+		resp := LogLevelResponse{Level: GetLevel()}
+		if revert.timer != nil {
+			at := revert.at
+			resp.RevertAt = &at
+		}
+		revert.Unlock()
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
+
+// changeLevel changes the log level of the global logger and schedules the revert to the previous log level.
+func changeLevel(logLevel string, revertAfter time.Duration) error {
+	revert.Lock()
+	defer revert.Unlock()
+	l := L()
+	previous := l.Level()
+	if err := l.SetLevel(logLevel); err != nil {
+		return err
+	}
+	Info(logLevelChangedMsg, zap.String("from", previous), zap.String("to", logLevel),
+		zap.Duration("revert_after", revertAfter))
+
+	if revert.timer != nil {
+		revert.timer.Stop()
+		previous = revert.level
+		revert.timer = nil
+	}
+	if revertAfter <= 0 {
+		return nil
+	}
+	revert.logger = l
+	revert.level = previous
+	revert.at = time.Now().Add(revertAfter)
+	var timer *time.Timer
+	timer = time.AfterFunc(revertAfter, func() {
+		revert.Lock()
+		defer revert.Unlock()
+		if revert.timer != timer {
+			return
+		}
+		revert.timer = nil
+		if err := revert.logger.SetLevel(revert.level); err != nil {
+			Error(logLevelRevertedMsg, err)
+			return
+		}
+		Info(logLevelRevertedMsg, zap.String("to", revert.level))
+	})
+	revert.timer = timer
+	return nil
+}
+
+// cancelRevert cancels the pending revert of the log level. The caller must hold the revert lock.
+func cancelRevert() {
+	if revert.timer != nil {
+		revert.timer.Stop()
+		revert.timer = nil
+	}
+}
@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newLogLevelRouter returns a router with the log level handler.
+func newLogLevelRouter() *gin.Engine {
+	r := newRouter()
+	r.GET(LogLevelPath, LogLevelHandler())
+	r.PUT(LogLevelPath, LogLevelHandler())
+	return r
+}
+
+// putLogLevel sends a log level request and returns the response.
+func putLogLevel(r *gin.Engine, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, LogLevelPath, strings.NewReader(body))
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestParseLevel(t *testing.T) {
+	for level, expected := range map[string]zapcore.Level{
+		"DEBUG": zap.DebugLevel,
+		"info":  zap.InfoLevel,
+		"Warn":  zap.WarnLevel,
+		"ERROR": zap.ErrorLevel,
+	} {
+		l, err := ParseLevel(level)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, l)
+	}
+	_, err := ParseLevel("TRACE")
+	assert.EqualError(t, err, "Invalid log level : TRACE")
+}
+
+func TestSetLevel(t *testing.T) {
+	configureMockLogger(DefaultLogLevel)
+	component := Named("component")
+
+	Debug("some hidden message")
+	assert.NoError(t, SetLevel("debug"))
+	assert.Equal(t, debugLogLevel, GetLevel())
+	assert.Equal(t, debugLogLevel, component.Level())
+	component.Debug("some debug message")
+
+	output := Sink.String()
+	t.Logf("output = %s", output)
+	assert.False(t, strings.Contains(output, "some hidden message"))
+	assert.True(t, strings.Contains(output, "some debug message"))
+
+	assert.Error(t, SetLevel("TRACE"))
+	assert.Equal(t, debugLogLevel, GetLevel())
+
+	l := NewFromZap(zap.NewNop())
+	assert.EqualError(t, l.SetLevel(debugLogLevel), levelNotChangeableErrMsg)
+	assert.Equal(t, "FATAL", l.Level())
+	configureMockLogger(DefaultLogLevel)
+}
+
+func TestLogLevelHandler(t *testing.T) {
+	configureMockLogger(DefaultLogLevel)
+	r := newLogLevelRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, LogLevelPath, nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"level":"INFO"}`, w.Body.String())
+
+	w = putLogLevel(r, `{"level":"warn"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"level":"WARN"}`, w.Body.String())
+	assert.Equal(t, "WARN", GetLevel())
+
+	for _, body := range []string{`{"level":"TRACE"}`, `{"level":"DEBUG","revert_after":"soon"}`,
+		`{"level":"DEBUG","revert_after":"-1m"}`, `invalid`} {
+		w = putLogLevel(r, body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Equal(t, "WARN", GetLevel())
+	}
+
+	r = newRouter()
+	r.Any(LogLevelPath, LogLevelHandler())
+	for _, method := range []string{http.MethodPost, http.MethodDelete, http.MethodPatch} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(method, LogLevelPath, strings.NewReader(`{"level":"DEBUG"}`))
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code, method)
+		assert.Equal(t, "GET, PUT", w.Header().Get("Allow"))
+		assert.Equal(t, "WARN", GetLevel())
+	}
+	configureMockLogger(DefaultLogLevel)
+}
+
+func TestLogLevelHandler_Revert(t *testing.T) {
+	configureMockLogger(DefaultLogLevel)
+	r := newLogLevelRouter()
+
+	w := putLogLevel(r, `{"level":"DEBUG","revert_after":"1h"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	resp := new(LogLevelResponse)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), resp))
+	assert.Equal(t, debugLogLevel, resp.Level)
+	if assert.NotNil(t, resp.RevertAt) {
+		assert.WithinDuration(t, time.Now().Add(time.Hour), *resp.RevertAt, time.Minute)
+	}
+
+	// a new change replaces the pending revert and keeps its log level
+	w = putLogLevel(r, `{"level":"ERROR","revert_after":"50ms"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "ERROR", GetLevel())
+	assert.Eventually(t, func() bool {
+		return GetLevel() == DefaultLogLevel
+	}, time.Second, 10*time.Millisecond)
+
+	// a change without a revert cancels the pending revert
+	putLogLevel(r, `{"level":"DEBUG","revert_after":"50ms"}`)
+	w = putLogLevel(r, `{"level":"WARN"}`)
+	assert.Equal(t, `{"level":"WARN"}`, w.Body.String())
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, "WARN", GetLevel())
+
+	// replacing the global logger cancels the pending revert
+	putLogLevel(r, `{"level":"DEBUG","revert_after":"50ms"}`)
+	configureMockLogger("ERROR")
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, LogLevelPath, nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, `{"level":"ERROR"}`, w.Body.String())
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, "ERROR", GetLevel())
+	configureMockLogger(DefaultLogLevel)
+}
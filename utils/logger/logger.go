@@ -8,32 +8,41 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	stackTraceFieldKey     = "stacktrace"
+	contextFieldKey        = "context"
+	redactedValue          = "REDACTED"
+	redactedQueryParamsKey = "redacted_query_params"
 )
 
 var (
-	logger                 *zap.Logger
 	DefaultLogLevel        = "INFO"
 	debugLogLevel          = "DEBUG"
 	AuthorizationHeaderKey = "Authorization"
+	// RedactedHeaderKeys are the headers whose values are removed from the debug logs.
+	RedactedHeaderKeys = []string{AuthorizationHeaderKey, "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
 )
 
 func init() {
-	SetLoggerConfig(GetLoggerConfig(DefaultLogLevel))
+	if err := SetLoggerConfig(GetLoggerConfig(DefaultLogLevel)); err != nil {
+		log.Println(err)
+		global.Store(NewFromZap(zap.NewNop()))
+	}
 }
 
 func GetLoggerConfig(logLevel string) zap.Config {
-	var zapLogLevel zapcore.Level
-	if logLevel == debugLogLevel {
-		zapLogLevel = zap.DebugLevel
-	} else {
-		zapLogLevel = zap.InfoLevel
-	}
 	return zap.Config{
-		Level:    zap.NewAtomicLevelAt(zapLogLevel),
+		Level:    zap.NewAtomicLevelAt(zapLevel(logLevel)),
 		Encoding: "json",
 		EncoderConfig: zapcore.EncoderConfig{
 			MessageKey:   "message",
 			LevelKey:     "level",
+			NameKey:      "logger",
 			TimeKey:      "time",
 			CallerKey:    "caller",
 			EncodeLevel:  zapcore.LowercaseLevelEncoder,
@@ -44,41 +53,115 @@ func GetLoggerConfig(logLevel string) zap.Config {
 	}
 }
 
-func SetLoggerConfig(cfg zap.Config) {
-	var err error
-	if logger, err = cfg.Build(); err != nil {
-		log.Fatalln("Unable to initialize logger: ", err)
+// SetLoggerConfig builds a logger from the configuration and replaces the global logger with it.
+// The method returns an error and keeps the current global logger if the configuration is not valid.
+func SetLoggerConfig(cfg zap.Config) error {
+	l, err := NewFromConfig(cfg)
+	if err != nil {
+		return err
 	}
+	return ReplaceGlobal(l)
+}
+
+// Debug logs the message on the debug level.
+func Debug(msg string, tags ...zapcore.Field) {
+	L().skip(1).Debug(msg, tags...)
+}
+
+// Debugf formats and logs the message on the debug level.
+func Debugf(format string, a ...interface{}) {
+	L().sugar(1).Debugf(format, a...)
 }
 
+// Debugw logs the message and the key/value pairs on the debug level, for example
+// Debugw("Request sent", "method", "GET", "status", 200).
+func Debugw(msg string, keysAndValues ...interface{}) {
+	L().sugar(1).Debugw(msg, keysAndValues...)
+}
+
+// Info logs the message on the info level.
 func Info(msg string, tags ...zapcore.Field) {
-	logger.WithOptions(zap.AddCallerSkip(1)).Info(msg, tags...)
+	L().skip(1).Info(msg, tags...)
 }
 
+// Infof formats and logs the message on the info level.
 func Infof(format string, a ...interface{}) {
-	logger.WithOptions(zap.AddCallerSkip(1)).Info(fmt.Sprintf(format, a...))
+	L().sugar(1).Infof(format, a...)
+}
+
+// Infow logs the message and the key/value pairs on the info level.
+func Infow(msg string, keysAndValues ...interface{}) {
+	L().sugar(1).Infow(msg, keysAndValues...)
 }
 
+// Warn logs the message on the warn level.
 func Warn(msg string, tags ...zapcore.Field) {
-	logger.WithOptions(zap.AddCallerSkip(1)).Warn(msg, tags...)
+	L().skip(1).Warn(msg, tags...)
 }
 
+// Warnf formats and logs the message on the warn level.
+func Warnf(format string, a ...interface{}) {
+	L().sugar(1).Warnf(format, a...)
+}
+
+// Warnw logs the message and the key/value pairs on the warn level.
+func Warnw(msg string, keysAndValues ...interface{}) {
+	L().sugar(1).Warnw(msg, keysAndValues...)
+}
+
+// Error logs the message and the error on the error level.
+// The stack trace and the key/value context captured by the errors package are logged as the "stacktrace" and
+// "context" fields.
 func Error(msg string, err error, tags ...zapcore.Field) {
-	if err != nil {
-		tags = append(tags, zap.NamedError("error", err))
-	}
-	logger.WithOptions(zap.AddCallerSkip(1)).Error(msg, tags...)
+	L().skip(1).Error(msg, errorFields(err, tags)...)
 }
 
-func Debug(msg string, tags ...zapcore.Field) {
-	logger.WithOptions(zap.AddCallerSkip(2)).Debug(msg, tags...)
+// Errorf formats and logs the message on the error level. The first error argument is also logged like the
+// error of Error.
+func Errorf(format string, a ...interface{}) {
+	L().skip(1).Error(fmt.Sprintf(format, a...), errorFields(firstError(a), nil)...)
 }
 
+// Errorw logs the message and the key/value pairs on the error level.
+func Errorw(msg string, keysAndValues ...interface{}) {
+	L().sugar(1).Errorw(msg, keysAndValues...)
+}
+
+// Panic logs the message on the panic level and panics.
 func Panic(msg string, tags ...zapcore.Field) {
-	logger.WithOptions(zap.AddCallerSkip(1)).Panic(msg, tags...)
+	L().skip(1).Panic(msg, tags...)
+}
+
+// Panicf formats and logs the message on the panic level and panics.
+func Panicf(format string, a ...interface{}) {
+	L().sugar(1).Panicf(format, a...)
+}
+
+// Panicw logs the message and the key/value pairs on the panic level and panics.
+func Panicw(msg string, keysAndValues ...interface{}) {
+	L().sugar(1).Panicw(msg, keysAndValues...)
+}
+
+// Fatal logs the message on the fatal level and exits the process with status 1.
+func Fatal(msg string, tags ...zapcore.Field) {
+	L().skip(1).Fatal(msg, tags...)
+}
+
+// Fatalf formats and logs the message on the fatal level and exits the process with status 1.
+func Fatalf(format string, a ...interface{}) {
+	L().sugar(1).Fatalf(format, a...)
+}
+
+// Fatalw logs the message and the key/value pairs on the fatal level and exits the process with status 1.
+func Fatalw(msg string, keysAndValues ...interface{}) {
+	L().sugar(1).Fatalw(msg, keysAndValues...)
 }
 
 // GinZap returns a gin.HandlerFunc (middleware) that logs requests using uber-go/zap.
+// The middleware adds the request id and the trace id of the request to the logger of the request context, so
+// that the logs of the handlers made with FromContext or the *Ctx functions can be correlated, see
+// requestContext.
+// The values of the query parameters that were marked with RedactQueryParam are removed from the logs.
 // Requests with errors are logged using zap.Error().
 // Requests without errors are logged using zap.Info().
 func GinZap() gin.HandlerFunc {
@@ -86,16 +169,19 @@ func GinZap() gin.HandlerFunc {
 		start := dateutils.GetDateTimeNow()
 		path := c.Request.URL.Path
 		query := c.Request.URL.RawQuery
+		requestContext(c)
 		c.Next()
+		query = redactQuery(query, c.GetStringSlice(redactedQueryParamsKey))
 		end := dateutils.GetDateTimeNow()
 		latency := end.Sub(start)
 
+		l := FromContext(c)
 		if len(c.Errors) > 0 {
 			for _, e := range c.Errors.Errors() {
-				logger.WithOptions(zap.AddCallerSkip(1)).Error(e)
+				l.skip(1).Error(e)
 			}
 		} else {
-			logger.Info(path,
+			l.zap.Info(path,
 				zap.Int("status", c.Writer.Status()),
 				zap.String("method", c.Request.Method),
 				zap.String("path", path),
@@ -108,11 +194,75 @@ func GinZap() gin.HandlerFunc {
 	}
 }
 
+// RedactQueryParam marks the query parameter of the request as sensitive, for example an API key, so that GinZap
+// removes its value from the request log.
+func RedactQueryParam(c *gin.Context, name string) {
+	c.Set(redactedQueryParamsKey, append(c.GetStringSlice(redactedQueryParamsKey), name))
+}
+
+// redactQuery returns the raw query with the values of the parameters replaced.
+func redactQuery(query string, params []string) string {
+	if query == "" || len(params) == 0 {
+		return query
+	}
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		key := strings.SplitN(pair, "=", 2)[0]
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		for _, param := range params {
+			if key == param {
+				pairs[i] = url.QueryEscape(key) + "=" + redactedValue
+				break
+			}
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// BodyRedactor returns a request or response body as it is written to the debug logs, for example without the
+// sensitive fields.
+type BodyRedactor func(body interface{}) interface{}
+
+// RestyDebugLogs logs the request and the response of a resty call on the debug level.
+// The values of the headers in RedactedHeaderKeys are removed from the logs, the bodies are logged as they are.
 func RestyDebugLogs(resp *resty.Response) {
-	resp.Request.Header[AuthorizationHeaderKey] = []string{}
-	Debug(fmt.Sprintf("Request: %v", resp.Request))
-	Debug(fmt.Sprintf("Request Url: %v", resp.Request.URL))
-	Debug(fmt.Sprintf("Request Header: %v", resp.Request.Header))
-	Debug(fmt.Sprintf("Request Body: %v", resp.Request.Body))
-	Debug(fmt.Sprintf("Response Body: %v", string(resp.Body())))
+	restyDebugLogs(resp, nil)
+}
+
+// RestyDebugLogsWithRedactor logs the request and the response of a resty call on the debug level like
+// RestyDebugLogs, with the bodies written through the redactor.
+func RestyDebugLogsWithRedactor(resp *resty.Response, redact BodyRedactor) {
+	restyDebugLogs(resp, redact)
+}
+
+// restyDebugLogs logs the request and the response of a resty call, the bodies are redacted if redact is not nil.
+func restyDebugLogs(resp *resty.Response, redact BodyRedactor) {
+	header := resp.Request.Header
+	if resp.Request.RawRequest != nil {
+		header = resp.Request.RawRequest.Header
+	}
+	var reqBody, respBody interface{} = resp.Request.Body, string(resp.Body())
+	if redact != nil {
+		reqBody, respBody = redact(reqBody), redact(respBody)
+	}
+	// the logs report the caller of RestyDebugLogs
+	l := L().sugar(2)
+	l.Debugf("Request: %s %s", resp.Request.Method, resp.Request.URL)
+	l.Debugf("Request Url: %v", resp.Request.URL)
+	l.Debugf("Request Header: %v", redactHeader(header))
+	l.Debugf("Request Body: %v", reqBody)
+	l.Debugf("Response Body: %v", respBody)
+}
+
+// redactHeader returns a copy of the header with the values of the sensitive headers removed.
+func redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, key := range RedactedHeaderKeys {
+		if _, ok := redacted[key]; ok {
+			redacted[key] = []string{}
+		}
+	}
+	return redacted
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/privatesquare/bkst-go-utils/utils/errors"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -120,6 +121,93 @@ func TestError(t *testing.T) {
 	assert.True(t, strings.Contains(output, msg))
 }
 
+func TestLoggingFunctions(t *testing.T) {
+	configureMockLogger(debugLogLevel)
+	defer configureMockLogger(DefaultLogLevel)
+	fatal := L()
+	assert.NoError(t, ReplaceGlobal(NewFromZap(fatal.Zap().WithOptions(zap.OnFatal(zapcore.WriteThenPanic)))))
+	defer ReplaceGlobal(fatal)
+
+	tests := []struct {
+		name  string
+		level string
+		log   func()
+	}{
+		{"Debug", "debug", func() { Debug("some message", zap.Int("count", 1)) }},
+		{"Debugf", "debug", func() { Debugf("some %s", "message") }},
+		{"Debugw", "debug", func() { Debugw("some message", "count", 1) }},
+		{"Info", "info", func() { Info("some message", zap.Int("count", 1)) }},
+		{"Infof", "info", func() { Infof("some %s", "message") }},
+		{"Infow", "info", func() { Infow("some message", "count", 1) }},
+		{"Warn", "warn", func() { Warn("some message", zap.Int("count", 1)) }},
+		{"Warnf", "warn", func() { Warnf("some %s", "message") }},
+		{"Warnw", "warn", func() { Warnw("some message", "count", 1) }},
+		{"Error", "error", func() { Error("some message", nil, zap.Int("count", 1)) }},
+		{"Errorf", "error", func() { Errorf("some %s", "message") }},
+		{"Errorw", "error", func() { Errorw("some message", "count", 1) }},
+		{"Panic", "panic", func() { Panic("some message", zap.Int("count", 1)) }},
+		{"Panicf", "panic", func() { Panicf("some %s", "message") }},
+		{"Panicw", "panic", func() { Panicw("some message", "count", 1) }},
+		{"Fatal", "fatal", func() { Fatal("some message", zap.Int("count", 1)) }},
+		{"Fatalf", "fatal", func() { Fatalf("some %s", "message") }},
+		{"Fatalw", "fatal", func() { Fatalw("some message", "count", 1) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Sink.Reset()
+			func() {
+				defer func() {
+					recovered := recover()
+					assert.Equal(t, tt.level == "panic" || tt.level == "fatal", recovered != nil)
+				}()
+				tt.log()
+			}()
+
+			// Assert sink contents
+			output := Sink.String()
+			t.Logf("output = %s", output)
+
+			assert.True(t, strings.Contains(output, "\"level\":\""+tt.level+"\""))
+			assert.True(t, strings.Contains(output, "\"message\":\"some message\""))
+			assert.True(t, strings.Contains(output, "\"caller\":\"logger/logger_test.go"))
+			if !strings.HasSuffix(tt.name, "f") {
+				assert.True(t, strings.Contains(output, "\"count\":1"))
+			}
+		})
+	}
+}
+
+func TestErrorf(t *testing.T) {
+	configureMockLogger(DefaultLogLevel)
+	errors.SetStackTraceEnabled(true)
+	defer errors.SetStackTraceEnabled(false)
+
+	Errorf("Unable to read the file %s: %v", "/tmp/file", errors.New("file not found"))
+
+	// Assert sink contents
+	output := Sink.String()
+	t.Logf("output = %s", output)
+
+	assert.True(t, strings.Contains(output, "\"message\":\"Unable to read the file /tmp/file: file not found\""))
+	assert.True(t, strings.Contains(output, "\"error\":\"file not found\""))
+	assert.True(t, strings.Contains(output, "\"stacktrace\":\"github.com/privatesquare/bkst-go-utils/utils/logger.TestErrorf"))
+}
+
+func TestError_StackTraceAndContext(t *testing.T) {
+	errors.SetStackTraceEnabled(true)
+	defer errors.SetStackTraceEnabled(false)
+
+	msg := "some traced error message"
+	Error(msg, errors.Wrap(errors.New("file not found"), msg, "path", "/tmp/file"))
+
+	// Assert sink contents
+	output := Sink.String()
+	t.Logf("output = %s", output)
+
+	assert.True(t, strings.Contains(output, "\"stacktrace\":\"github.com/privatesquare/bkst-go-utils/utils/logger.TestError_StackTraceAndContext"))
+	assert.True(t, strings.Contains(output, "\"context\":{\"path\":\"/tmp/file\"}"))
+}
+
 func TestGinZap(t *testing.T) {
 	r := newRouter()
 
@@ -142,6 +230,25 @@ func TestGinZap(t *testing.T) {
 	assert.True(t, strings.Contains(output, "\"status\":200"))
 }
 
+func TestGinZap_RedactQueryParam(t *testing.T) {
+	configureMockLogger(DefaultLogLevel)
+	r := newRouter()
+	r.GET("/test", func(ctx *gin.Context) {
+		RedactQueryParam(ctx, "api_key")
+		ctx.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/test?page=2&api%5Fkey=secret-key&api_key=other-key", nil)
+	r.ServeHTTP(w, req)
+
+	output := Sink.String()
+	t.Logf("output = %s", output)
+	assert.True(t, strings.Contains(output, `"query":"page=2&api_key=REDACTED&api_key=REDACTED"`))
+	assert.False(t, strings.Contains(output, "secret-key"))
+	assert.False(t, strings.Contains(output, "other-key"))
+}
+
 func TestGinZapError(t *testing.T) {
 	r := newRouter()
 
@@ -174,7 +281,7 @@ func TestRestyDebugLogs(t *testing.T) {
 	responder := httpmock.NewStringResponder(http.StatusOK, "someString")
 	httpmock.RegisterResponder(http.MethodGet, "/", responder)
 
-	resp, err := client.R().Get("/")
+	resp, err := client.R().SetAuthToken("secret-token").SetHeader("Cookie", "session=secret").Get("/")
 	assert.NoError(t, err)
 
 	configureMockLogger(debugLogLevel)
@@ -185,10 +292,42 @@ func TestRestyDebugLogs(t *testing.T) {
 	output := Sink.String()
 	t.Logf("output = %s", output)
 
+	assert.True(t, strings.Contains(output, "\"caller\":\"logger/logger_test.go"))
 	assert.True(t, strings.Contains(output, "Request Url: "+baseUrl))
 	assert.True(t, strings.Contains(output, "Request Header: map[Authorization:[]"))
 	assert.True(t, strings.Contains(output, "Request Body: <nil>"))
 	assert.True(t, strings.Contains(output, "someString"))
+	assert.False(t, strings.Contains(output, "secret"))
+	assert.Equal(t, "Bearer secret-token", resp.Request.RawRequest.Header.Get(AuthorizationHeaderKey))
+	configureMockLogger(debugLogLevel)
+}
+
+func TestRestyDebugLogs_BodyRedactor(t *testing.T) {
+	client := resty.New().SetHostURL(baseUrl)
+	httpmock.ActivateNonDefault(client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	responder := httpmock.NewStringResponder(http.StatusOK, `{"token":"secret-token"}`)
+	httpmock.RegisterResponder(http.MethodPost, "/", responder)
+
+	resp, err := client.R().SetBody(map[string]string{"username": "admin", "password": "secret"}).Post("/")
+	assert.NoError(t, err)
+
+	configureMockLogger(debugLogLevel)
+	RestyDebugLogsWithRedactor(resp, func(body interface{}) interface{} {
+		if m, ok := body.(map[string]string); ok {
+			return m["username"]
+		}
+		return len(body.(string))
+	})
+	output := Sink.String()
+	t.Logf("output = %s", output)
+
+	assert.True(t, strings.Contains(output, "\"caller\":\"logger/logger_test.go"))
+	assert.False(t, strings.Contains(output, AuthorizationHeaderKey))
+	assert.True(t, strings.Contains(output, "Request Body: admin"))
+	assert.True(t, strings.Contains(output, "Response Body: 24"))
+	assert.False(t, strings.Contains(output, "secret"))
 	configureMockLogger(debugLogLevel)
 }
 
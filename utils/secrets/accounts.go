@@ -0,0 +1,286 @@
+package secrets
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/privatesquare/bkst-go-utils/utils/fileutils"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	BcryptHashAlgorithm   = "bcrypt"
+	Argon2idHashAlgorithm = "argon2id"
+	PBKDF2HashAlgorithm   = "pbkdf2-sha256"
+
+	argon2idHashFormat = "$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s"
+	pbkdf2HashFormat   = "$pbkdf2-sha256$%d$%s$%s"
+	argon2idMemory     = 64 * 1024
+	argon2idTime       = 1
+	argon2idThreads    = 4
+	argon2idKeyLength  = 32
+	pbkdf2Iterations   = 310000
+	pbkdf2KeyLength    = 32
+	saltLength         = 16
+
+	unsupportedHashAlgorithmErrMsg = "unsupported password hash algorithm : %s"
+	invalidPasswordHashErrMsg      = "invalid password hash for the account '%s'"
+	invalidAccountLineErrMsg       = "invalid account on line %d : expected 'username:hash'"
+	duplicateAccountErrMsg         = "duplicate account '%s' on line %d"
+)
+
+var (
+	dummyHash     string
+	dummyHashOnce sync.Once
+)
+
+// AccountStore authenticates user accounts.
+type AccountStore interface {
+	// Authenticate checks if the password matches the password of the user account.
+	// The method returns false if the user does not exist or the password does not match.
+	Authenticate(username, password string) (bool, error)
+}
+
+// PlainAccountStore is an AccountStore of plaintext passwords by username.
+// The passwords are compared in constant time, but prefer a HashedAccountStore to avoid keeping
+// plaintext passwords in memory and configuration.
+type PlainAccountStore map[string]string
+
+// HashedAccountStore is an AccountStore of bcrypt, argon2id or PBKDF2 password hashes by username.
+type HashedAccountStore struct {
+	hashes map[string]string
+	// dummyHash is verified for unknown users, it has the algorithm and the parameters of a stored hash.
+	dummyHash string
+}
+
+// Authenticate compares the passwords in constant time.
+func (s PlainAccountStore) Authenticate(username, password string) (bool, error) {
+	stored, ok := s[username]
+	// the hashes have the same length, so the comparison takes the same time for every password
+	storedHash, passwordHash := sha256.Sum256([]byte(stored)), sha256.Sum256([]byte(password))
+	return subtle.ConstantTimeCompare(storedHash[:], passwordHash[:]) == 1 && ok, nil
+}
+
+// NewHashedAccountStore returns a HashedAccountStore for the password hashes by username.
+// The dummy hash for unknown users is built with the algorithm and the parameters of the hash of the first
+// username in sorted order, so that unknown users take as long to verify as the known users.
+// The method returns an error if a hash is not a valid bcrypt, argon2id or PBKDF2 hash.
+func NewHashedAccountStore(accounts map[string]string) (*HashedAccountStore, error) {
+	hashes := make(map[string]string, len(accounts))
+	usernames := make([]string, 0, len(accounts))
+	for username, hash := range accounts {
+		if _, err := hashAlgorithm(hash); err != nil {
+			return nil, errors.Newf(invalidPasswordHashErrMsg, username)
+		}
+		hashes[username] = hash
+		usernames = append(usernames, username)
+	}
+	s := &HashedAccountStore{hashes: hashes}
+	if len(usernames) > 0 {
+		sort.Strings(usernames)
+		dummy, err := newDummyHash(hashes[usernames[0]])
+		if err != nil {
+			return nil, err
+		}
+		s.dummyHash = dummy
+	}
+	return s, nil
+}
+
+// LoadHashedAccountStore reads a htpasswd style file with one "username:hash" account per line.
+// Empty lines and lines starting with "#" are ignored.
+func LoadHashedAccountStore(filePath string) (*HashedAccountStore, error) {
+	f, err := fileutils.OpenFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadHashedAccountStore(f)
+}
+
+// ReadHashedAccountStore reads the htpasswd style accounts from the reader.
+func ReadHashedAccountStore(r io.Reader) (*HashedAccountStore, error) {
+	accounts := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		account := strings.SplitN(text, ":", 2)
+		if len(account) != 2 || account[0] == "" || account[1] == "" {
+			return nil, errors.Newf(invalidAccountLineErrMsg, line)
+		}
+		if _, ok := accounts[account[0]]; ok {
+			return nil, errors.Newf(duplicateAccountErrMsg, account[0], line)
+		}
+		accounts[account[0]] = account[1]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewHashedAccountStore(accounts)
+}
+
+// Authenticate verifies the password against the hash of the user account. A dummy hash is verified for
+// unknown users so that the response time does not reveal which users exist.
+func (s *HashedAccountStore) Authenticate(username, password string) (bool, error) {
+	hash, ok := s.hashes[username]
+	if !ok {
+		dummy := s.dummyHash
+		if dummy == "" {
+			dummy = getDummyHash()
+		}
+		_, _ = VerifyPasswordHash(password, dummy)
+		return false, nil
+	}
+	return VerifyPasswordHash(password, hash)
+}
+
+// HashPassword returns the hash of the password for the algorithm, with a random salt.
+// The hashes use the modular crypt format, for example "$2a$10$..." for bcrypt,
+// "$argon2id$v=19$m=65536,t=1,p=4$<salt>$<key>" for argon2id and "$pbkdf2-sha256$<iterations>$<salt>$<key>"
+// for PBKDF2, where the salt and the key are unpadded base64 strings.
+func HashPassword(password, algorithm string) (string, error) {
+	if algorithm == BcryptHashAlgorithm {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		return string(hash), err
+	}
+	salt := make([]byte, saltLength)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", err
+	}
+	b64 := base64.RawStdEncoding.EncodeToString
+	switch algorithm {
+	case Argon2idHashAlgorithm:
+		key := argon2.IDKey([]byte(password), salt, argon2idTime, argon2idMemory, argon2idThreads, argon2idKeyLength)
+		return fmt.Sprintf(argon2idHashFormat, argon2.Version, argon2idMemory, argon2idTime, argon2idThreads,
+			b64(salt), b64(key)), nil
+	case PBKDF2HashAlgorithm:
+		key := pbkdf2.Key([]byte(password), salt, pbkdf2Iterations, pbkdf2KeyLength, sha256.New)
+		return fmt.Sprintf(pbkdf2HashFormat, pbkdf2Iterations, b64(salt), b64(key)), nil
+	}
+	return "", errors.Newf(unsupportedHashAlgorithmErrMsg, algorithm)
+}
+
+// VerifyPasswordHash checks if the password matches a bcrypt, argon2id or PBKDF2 hash in constant time.
+func VerifyPasswordHash(password, hash string) (bool, error) {
+	algorithm, err := hashAlgorithm(hash)
+	if err != nil {
+		return false, err
+	}
+	if algorithm == BcryptHashAlgorithm {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	var key, expected []byte
+	parts := strings.Split(hash, "$")
+	salt, _ := base64.RawStdEncoding.DecodeString(parts[len(parts)-2])
+	expected, _ = base64.RawStdEncoding.DecodeString(parts[len(parts)-1])
+	switch algorithm {
+	case Argon2idHashAlgorithm:
+		var memory, time uint32
+		var threads uint8
+		_, _ = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads)
+		key = argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(expected)))
+	case PBKDF2HashAlgorithm:
+		iterations, _ := strconv.Atoi(parts[2])
+		key = pbkdf2.Key([]byte(password), salt, iterations, len(expected), sha256.New)
+	}
+	return subtle.ConstantTimeCompare(key, expected) == 1, nil
+}
+
+// hashAlgorithm returns the algorithm of a password hash and checks if the hash is well-formed.
+func hashAlgorithm(hash string) (string, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) < 2 {
+		return "", errors.Newf(unsupportedHashAlgorithmErrMsg, "")
+	}
+	invalid := errors.Newf(unsupportedHashAlgorithmErrMsg, parts[1])
+	validB64 := func(values ...string) bool {
+		for _, v := range values {
+			if b, err := base64.RawStdEncoding.DecodeString(v); err != nil || len(b) == 0 {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch parts[1] {
+	case "2a", "2b", "2y":
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return "", invalid
+		}
+		return BcryptHashAlgorithm, nil
+	case Argon2idHashAlgorithm:
+		var version int
+		var memory, time uint32
+		var threads uint8
+		if len(parts) != 6 || !validB64(parts[4], parts[5]) {
+			return "", invalid
+		}
+		if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+			return "", invalid
+		}
+		if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil ||
+			memory == 0 || time == 0 || threads == 0 {
+			return "", invalid
+		}
+		return Argon2idHashAlgorithm, nil
+	case PBKDF2HashAlgorithm:
+		if len(parts) != 5 || !validB64(parts[3], parts[4]) {
+			return "", invalid
+		}
+		if iterations, err := strconv.Atoi(parts[2]); err != nil || iterations <= 0 {
+			return "", invalid
+		}
+		return PBKDF2HashAlgorithm, nil
+	}
+	return "", invalid
+}
+
+// newDummyHash returns a hash of a random password with the algorithm and the parameters of the hash.
+func newDummyHash(hash string) (string, error) {
+	algorithm, err := hashAlgorithm(hash)
+	if err != nil {
+		return "", err
+	}
+	if algorithm == BcryptHashAlgorithm {
+		cost, _ := bcrypt.Cost([]byte(hash))
+		dummy, err := bcrypt.GenerateFromPassword([]byte(GetRandomPassword()), cost)
+		return string(dummy), err
+	}
+	// the salt and the key are replaced with random values of the same length
+	parts := strings.Split(hash, "$")
+	for _, i := range []int{len(parts) - 2, len(parts) - 1} {
+		b, _ := base64.RawStdEncoding.DecodeString(parts[i])
+		if _, err := io.ReadFull(rand.Reader, b); err != nil {
+			return "", err
+		}
+		parts[i] = base64.RawStdEncoding.EncodeToString(b)
+	}
+	return strings.Join(parts, "$"), nil
+}
+
+// getDummyHash returns the bcrypt hash that is verified for unknown users of an empty store.
+func getDummyHash() string {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = HashPassword(GetRandomPassword(), BcryptHashAlgorithm)
+	})
+	return dummyHash
+}
@@ -0,0 +1,126 @@
+package secrets
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHashPassword(t *testing.T) {
+	for _, algorithm := range []string{BcryptHashAlgorithm, Argon2idHashAlgorithm, PBKDF2HashAlgorithm} {
+		hash, err := HashPassword("s3cret", algorithm)
+		assert.NoError(t, err)
+
+		ok, err := VerifyPasswordHash("s3cret", hash)
+		assert.NoError(t, err)
+		assert.True(t, ok, algorithm)
+
+		ok, err = VerifyPasswordHash("other", hash)
+		assert.NoError(t, err)
+		assert.False(t, ok, algorithm)
+
+		other, _ := HashPassword("s3cret", algorithm)
+		assert.NotEqual(t, hash, other)
+	}
+
+	_, err := HashPassword("s3cret", "md5")
+	assert.EqualError(t, err, fmt.Sprintf(unsupportedHashAlgorithmErrMsg, "md5"))
+}
+
+func TestHashAlgorithm(t *testing.T) {
+	hashes := []string{
+		"$2y$05$QSmLqJPo5zuRrFSZK0e.1uhnUYiurgD/W0UNu9n0OEvDeIKzd2hzK",
+		"$argon2id$v=19$m=16,t=2,p=1$c29tZXNhbHQ$kvgWaxAWhRBhdwPi49Vp4g",
+		"$pbkdf2-sha256$1000$c29tZXNhbHQ$YtIYwZw3DX3ODUnUkc19yFTg7J2LJjc92EwW3tLtBdQ",
+	}
+	for i, algorithm := range []string{BcryptHashAlgorithm, Argon2idHashAlgorithm, PBKDF2HashAlgorithm} {
+		alg, err := hashAlgorithm(hashes[i])
+		assert.NoError(t, err)
+		assert.Equal(t, algorithm, alg)
+	}
+
+	_, err := VerifyPasswordHash("password", "plaintext")
+	assert.Error(t, err)
+	_, err = VerifyPasswordHash("password", "$pbkdf2-sha256$0$c29tZXNhbHQ$YtIY")
+	assert.Error(t, err)
+	_, err = VerifyPasswordHash("password", "$argon2id$v=19$m=16,t=2$c29tZXNhbHQ$kvgWaxAWhRBhdwPi49Vp4g")
+	assert.Error(t, err)
+}
+
+func TestPlainAccountStore(t *testing.T) {
+	store := PlainAccountStore{"admin": "admin", "empty": ""}
+	ok, err := store.Authenticate("admin", "admin")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, _ = store.Authenticate("admin", "admi")
+	assert.False(t, ok)
+	ok, _ = store.Authenticate("unknown", "")
+	assert.False(t, ok)
+}
+
+func TestHashedAccountStore(t *testing.T) {
+	bcryptHash, _ := HashPassword("admin", BcryptHashAlgorithm)
+	argonHash, _ := HashPassword("user", Argon2idHashAlgorithm)
+	pbkdf2Hash, _ := HashPassword("other", PBKDF2HashAlgorithm)
+	data := strings.Join([]string{
+		"# accounts",
+		"admin:" + bcryptHash,
+		"",
+		"user:" + argonHash,
+		"other:" + pbkdf2Hash,
+	}, "\n")
+	filePath := filepath.Join(t.TempDir(), ".htpasswd")
+	assert.NoError(t, os.WriteFile(filePath, []byte(data), 0600))
+
+	store, err := LoadHashedAccountStore(filePath)
+	assert.NoError(t, err)
+	for username, password := range map[string]string{"admin": "admin", "user": "user", "other": "other"} {
+		ok, err := store.Authenticate(username, password)
+		assert.NoError(t, err)
+		assert.True(t, ok, username)
+
+		ok, err = store.Authenticate(username, password+"x")
+		assert.NoError(t, err)
+		assert.False(t, ok, username)
+	}
+	ok, err := store.Authenticate("unknown", "admin")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = ReadHashedAccountStore(strings.NewReader("admin"))
+	assert.EqualError(t, err, fmt.Sprintf(invalidAccountLineErrMsg, 1))
+	_, err = ReadHashedAccountStore(strings.NewReader("admin:" + bcryptHash + "\nadmin:" + bcryptHash))
+	assert.EqualError(t, err, fmt.Sprintf(duplicateAccountErrMsg, "admin", 2))
+	_, err = NewHashedAccountStore(map[string]string{"admin": "admin"})
+	assert.EqualError(t, err, fmt.Sprintf(invalidPasswordHashErrMsg, "admin"))
+	_, err = LoadHashedAccountStore(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestHashedAccountStore_DummyHash(t *testing.T) {
+	for _, algorithm := range []string{BcryptHashAlgorithm, Argon2idHashAlgorithm, PBKDF2HashAlgorithm} {
+		hash, _ := HashPassword("admin", algorithm)
+		store, err := NewHashedAccountStore(map[string]string{"admin": hash})
+		assert.NoError(t, err)
+
+		// the dummy hash has the algorithm and the parameters of the stored hash
+		dummyAlgorithm, err := hashAlgorithm(store.dummyHash)
+		assert.NoError(t, err)
+		assert.Equal(t, algorithm, dummyAlgorithm)
+		assert.NotEqual(t, hash, store.dummyHash)
+		parts, dummyParts := strings.Split(hash, "$"), strings.Split(store.dummyHash, "$")
+		assert.Equal(t, parts[:len(parts)-2], dummyParts[:len(dummyParts)-2])
+		assert.Equal(t, len(hash), len(store.dummyHash))
+	}
+
+	store, err := NewHashedAccountStore(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, store.dummyHash)
+	ok, err := store.Authenticate("unknown", "admin")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
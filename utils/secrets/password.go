@@ -18,6 +18,7 @@ const (
 	invalidPasswordErrMsg    = "password should be at least 8 characters long with at least one number, one uppercase letter, one lowercase letter and one special character"
 	passwordEncryptionErrMsg = "password encryption error: %v"
 	passwordDecryptionErrMsg = "password decryption error: %v"
+	ciphertextTooShortErrMsg = "ciphertext is too short"
 )
 
 var (
@@ -120,6 +121,9 @@ func DecryptPassword(data, passphrase string) (string, error) {
 		return "", PasswordDecryptionError{Err: err}
 	}
 	nonceSize := gcm.NonceSize()
+	if len(bData) < nonceSize {
+		return "", PasswordDecryptionError{Err: errors.New(ciphertextTooShortErrMsg)}
+	}
 	nonce, ciphertext := bData[:nonceSize], bData[nonceSize:]
 	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
 	if err != nil {
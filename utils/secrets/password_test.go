@@ -87,4 +87,7 @@ func TestDecryptPassword(t *testing.T) {
 
 	dPass, err = DecryptPassword(ePass, "notValid")
 	assert.Error(t, err)
+
+	dPass, err = DecryptPassword("c2hvcnQ=", passphrase)
+	assert.Error(t, err)
 }
@@ -0,0 +1,53 @@
+package secrets
+
+import (
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/privatesquare/bkst-go-utils/utils/fileutils"
+	"os"
+	"strings"
+)
+
+const (
+	EnvSecretPrefix  = "env:"
+	FileSecretPrefix = "file:"
+
+	secretEnvNotFoundErrMsg = "secret environment variable '%s' is not set"
+	emptySecretErrMsg       = "secret '%s' is empty"
+)
+
+// LoadSecret returns the value of a secret reference.
+// A reference "env:NAME" is read from the environment variable NAME and a reference "file:/path" from the file,
+// without the trailing newline. Other references are returned as they are.
+// The method returns an error if the secret cannot be read or is empty.
+func LoadSecret(ref string) (string, error) {
+	var secret string
+	switch {
+	case strings.HasPrefix(ref, EnvSecretPrefix):
+		name := strings.TrimPrefix(ref, EnvSecretPrefix)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", errors.Newf(secretEnvNotFoundErrMsg, name)
+		}
+		secret = value
+	case strings.HasPrefix(ref, FileSecretPrefix):
+		data, err := fileutils.ReadFile(strings.TrimPrefix(ref, FileSecretPrefix))
+		if err != nil {
+			return "", err
+		}
+		secret = strings.TrimRight(string(data), "\r\n")
+	default:
+		secret = ref
+	}
+	if secret == "" {
+		return "", errors.Newf(emptySecretErrMsg, secretName(ref))
+	}
+	return secret, nil
+}
+
+// secretName returns the reference without the value of a literal secret.
+func secretName(ref string) string {
+	if strings.HasPrefix(ref, EnvSecretPrefix) || strings.HasPrefix(ref, FileSecretPrefix) {
+		return ref
+	}
+	return ""
+}
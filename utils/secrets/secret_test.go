@@ -0,0 +1,40 @@
+package secrets
+
+import (
+	"fmt"
+	"github.com/privatesquare/bkst-go-utils/utils/errors"
+	"github.com/stretchr/testify/assert"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSecret(t *testing.T) {
+	secret, err := LoadSecret("literal")
+	assert.NoError(t, err)
+	assert.Equal(t, "literal", secret)
+
+	t.Setenv("TEST_SECRET", "from-env")
+	secret, err = LoadSecret("env:TEST_SECRET")
+	assert.NoError(t, err)
+	assert.Equal(t, "from-env", secret)
+
+	_, err = LoadSecret("env:TEST_MISSING_SECRET")
+	assert.EqualError(t, err, fmt.Sprintf(secretEnvNotFoundErrMsg, "TEST_MISSING_SECRET"))
+
+	filePath := filepath.Join(t.TempDir(), "secret")
+	assert.NoError(t, os.WriteFile(filePath, []byte("from-file\n"), 0600))
+	secret, err = LoadSecret("file:" + filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "from-file", secret)
+
+	_, err = LoadSecret("file:" + filepath.Join(t.TempDir(), "missing"))
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	_, err = LoadSecret("")
+	assert.EqualError(t, err, fmt.Sprintf(emptySecretErrMsg, ""))
+	t.Setenv("TEST_EMPTY_SECRET", "")
+	_, err = LoadSecret("env:TEST_EMPTY_SECRET")
+	assert.EqualError(t, err, fmt.Sprintf(emptySecretErrMsg, "env:TEST_EMPTY_SECRET"))
+}